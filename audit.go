@@ -0,0 +1,168 @@
+package basic
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// auditRecord is a single authentication decision written to `AuditWriter` as a line of JSON.
+type auditRecord struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Username     string    `json:"username"`
+	ClientIP     string    `json:"clientIp"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Result       string    `json:"result"`
+	Reason       string    `json:"reason,omitempty"`
+	RequestID    string    `json:"requestId,omitempty"`
+	PasswordHash string    `json:"passwordHash,omitempty"`
+	Level        string    `json:"level,omitempty"`
+	Elapsed      string    `json:"elapsed,omitempty"`
+}
+
+// auditQueueSize is the number of audit records waiting to be written before new ones are dropped.
+const auditQueueSize = 1024
+
+// audit writes an authentication decision to `AuditWriter` as newline-delimited JSON. The record is encoded
+// on the request path, and queued for `writeAudit`, so the request never waits for the writer. Passwords are
+// never written. Decisions slower than `SlowAuthThreshold` are written as warnings, with the time they took.
+func (a *BasicAuth) audit(r *http.Request, decided time.Time, username, passwordHash string, res result, elapsed time.Duration) {
+	if a.AuditWriter == nil {
+		return
+	}
+
+	var level, duration string
+	if a.isSlow(elapsed) {
+		level = "warn"
+		duration = elapsed.String()
+	}
+
+	requestID, _ := RequestIDFromContext(r.Context())
+	record, err := json.Marshal(auditRecord{
+		Timestamp:    decided.UTC(),
+		Username:     username,
+		ClientIP:     clientIP(r),
+		Method:       r.Method,
+		Path:         r.URL.Path,
+		Result:       res.String(),
+		Reason:       res.message(),
+		RequestID:    requestID,
+		PasswordHash: passwordHash,
+		Level:        level,
+		Elapsed:      duration,
+	})
+	if err != nil {
+		return
+	}
+
+	a.queueAudit(append(record, '\n'))
+}
+
+// queueAudit queues an audit record, and starts `writeAudit` if it is not running. If the queue is full, as the
+// writer cannot keep up, the record is dropped and counted in `Stats`, so a slow writer never stalls the requests.
+func (a *BasicAuth) queueAudit(record []byte) {
+	a.auditMu.Lock()
+	if len(a.auditQueue) >= auditQueueSize {
+		a.auditMu.Unlock()
+		atomic.AddUint64(&a.stats.auditDropped, 1)
+		return
+	}
+
+	a.auditQueue = append(a.auditQueue, record)
+	start := !a.auditWriting
+	a.auditWriting = true
+	a.auditMu.Unlock()
+
+	if start {
+		go a.writeAudit()
+	}
+}
+
+// writeAudit writes the queued audit records to `AuditWriter` in order, one at a time, without holding the lock
+// while writing. It returns once the queue is empty, so no goroutine is left behind while there is nothing to
+// write. Errors are ignored on purpose, as a broken audit trail should not break the protected endpoint.
+func (a *BasicAuth) writeAudit() {
+	for {
+		a.auditMu.Lock()
+		if len(a.auditQueue) == 0 {
+			a.auditQueue = nil
+			a.auditWriting = false
+			a.auditIdle().Broadcast()
+			a.auditMu.Unlock()
+			return
+		}
+
+		record := a.auditQueue[0]
+		a.auditQueue[0] = nil
+		a.auditQueue = a.auditQueue[1:]
+		writer := a.AuditWriter
+		a.auditMu.Unlock()
+
+		if writer != nil {
+			_, _ = writer.Write(record)
+		}
+	}
+}
+
+// FlushAudit blocks until the audit records queued so far have been written to `AuditWriter`, such as before
+// shutting down, or before reading the audit trail in tests.
+func (a *BasicAuth) FlushAudit() {
+	a.auditMu.Lock()
+	defer a.auditMu.Unlock()
+
+	for a.auditWriting {
+		a.auditIdle().Wait()
+	}
+}
+
+// auditIdle returns the condition signalled once the audit writer is idle. Must be called with `auditMu` held.
+func (a *BasicAuth) auditIdle() *sync.Cond {
+	if a.auditCond == nil {
+		a.auditCond = sync.NewCond(&a.auditMu)
+	}
+
+	return a.auditCond
+}
+
+// attemptedPasswordHash returns the hash of the password of a failed attempt if `AuditPasswordHashes` is set, or an
+// empty string otherwise. It is the HMAC-SHA256 of the password under the random key of the process, truncated to
+// 64 bits and encoded in hex. The same password always gives the same hash within a process, so that it can be spotted
+// across usernames, but hashes cannot be reversed with precomputed tables, nor correlated across processes. The second
+// factor of users with a TOTP secret is not part of the hash, as it changes with every attempt.
+func (a *BasicAuth) attemptedPasswordHash(r *http.Request, res result) string {
+	if !a.AuditPasswordHashes || res == resultSuccess || a.AuditWriter == nil && a.Logger == nil {
+		return ""
+	}
+
+	username, password, ok := a.parseCredentials(r)
+	if !ok {
+		return ""
+	}
+
+	if withoutCode, _, ok := a.splitTOTP(a.normalizeUsername(username), password); ok {
+		password = withoutCode
+	}
+
+	mac := hmac.New(sha256.New, processKey)
+	mac.Write([]byte("password-hash\x00"))
+	mac.Write([]byte(password))
+
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// clientIP returns the IP address of the client that sent the request.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
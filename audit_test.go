@@ -0,0 +1,293 @@
+package basic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Tests the shape of the JSON lines written to the audit trail.
+func TestAuditWriter(t *testing.T) {
+	users := map[string]string{"gerysantoso": "gerysantoso"}
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		expectedResult string
+		expectedReason string
+	}{
+		{
+			name:           "test_success",
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			expectedResult: "success",
+			expectedReason: "",
+		},
+		{
+			name:           "test_invalid_credentials",
+			username:       "gerysantoso",
+			password:       "wrong_password",
+			expectedResult: "invalid_credentials",
+			expectedReason: invalidCredentialsMessage,
+		},
+		{
+			name:           "test_missing_credentials",
+			username:       "",
+			password:       "",
+			expectedResult: "missing_credentials",
+			expectedReason: invalidSchemeMessage,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			auth := NewDefaultBasicAuth(users)
+			auth.AuditWriter = buf
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodPost, "/private", nil)
+			r.RemoteAddr = "192.0.2.1:1234"
+			w := httptest.NewRecorder()
+
+			if tc.username != "" && tc.password != "" {
+				r.SetBasicAuth(tc.username, tc.password)
+			}
+
+			handler(w, r)
+			auth.FlushAudit()
+
+			if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+				t.Fatalf("Expected exactly one JSON line, got: %q.", buf.String())
+			}
+
+			record := map[string]string{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("Failed to decode the audit record: %v.", err)
+			}
+
+			expected := map[string]string{
+				"username": tc.username,
+				"clientIp": "192.0.2.1",
+				"method":   http.MethodPost,
+				"path":     "/private",
+				"result":   tc.expectedResult,
+				"reason":   tc.expectedReason,
+			}
+			for key, value := range expected {
+				if record[key] != value {
+					t.Errorf("Expected and actual '%s' values are different! Expected: %v. Got: %v.", key, value, record[key])
+				}
+			}
+
+			if record["timestamp"] == "" {
+				t.Error("Expected a timestamp in the audit record.")
+			}
+
+			if _, ok := record["password"]; ok {
+				t.Error("Expected the password to never be written to the audit record.")
+			}
+		})
+	}
+}
+
+// Tests that the same wrong password gives the same hash in the audit trail within a process, whatever the username.
+func TestAuditPasswordHashes(t *testing.T) {
+	attempts := []struct {
+		username string
+		password string
+	}{
+		{username: "gerysantoso", password: "winter2023"},
+		{username: "nicholasdwiarto", password: "winter2023"},
+		{username: "gerysantoso", password: "summer2023"},
+		{username: "gerysantoso", password: "gerysantoso"},
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+	}{
+		{name: "test_enabled", enabled: true},
+		{name: "test_disabled", enabled: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			var events []AuthEvent
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			auth.AuditWriter = buf
+			auth.AuditPasswordHashes = tc.enabled
+			auth.Logger = func(event AuthEvent) { events = append(events, event) }
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			for _, attempt := range attempts {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.SetBasicAuth(attempt.username, attempt.password)
+				handler(httptest.NewRecorder(), r)
+			}
+			auth.FlushAudit()
+
+			if bytes.Contains(buf.Bytes(), []byte("2023")) {
+				t.Fatalf("Expected the passwords to never be written to the audit trail. Got: %s.", buf.String())
+			}
+
+			var hashes []string
+			scanner := bufio.NewScanner(buf)
+			for i := 0; scanner.Scan() && i < len(events); i++ {
+				record := map[string]string{}
+				if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+					t.Fatalf("Failed to decode the audit record: %v.", err)
+				}
+
+				if record["passwordHash"] != events[i].PasswordHash {
+					t.Errorf("Expected and actual hashes of the event are different! Expected: %v. Got: %v.", record["passwordHash"], events[i].PasswordHash)
+				}
+				hashes = append(hashes, record["passwordHash"])
+			}
+
+			if len(hashes) != len(attempts) {
+				t.Fatalf("Expected and actual numbers of audit records are different! Expected: %v. Got: %v.", len(attempts), len(hashes))
+			}
+
+			if !tc.enabled {
+				for _, hash := range hashes {
+					if hash != "" {
+						t.Errorf("Expected no password hash to be recorded. Got: %v.", hash)
+					}
+				}
+				return
+			}
+
+			if len(hashes[0]) != 16 || hashes[0] != hashes[1] {
+				t.Errorf("Expected the same password to give the same 16-character hash. Got: %v and %v.", hashes[0], hashes[1])
+			}
+
+			if hashes[2] == hashes[0] {
+				t.Errorf("Expected different passwords to give different hashes. Got: %v twice.", hashes[2])
+			}
+
+			if hashes[3] != "" {
+				t.Errorf("Expected no password hash to be recorded for a success. Got: %v.", hashes[3])
+			}
+		})
+	}
+}
+
+// Tests that the second factor of users with a TOTP secret is left out of the hash of their password.
+func TestAuditPasswordHashesWithTOTP(t *testing.T) {
+	var hashes []string
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso", "nicholasdwiarto": "nicholasdwiarto"})
+	auth.AuditPasswordHashes = true
+	auth.UserTOTP = map[string]string{"gerysantoso": "gezd gnbv gy3t qojq gezd gnbv gy3t qojq"}
+	auth.Logger = func(event AuthEvent) { hashes = append(hashes, event.PasswordHash) }
+
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	for _, attempt := range [][2]string{
+		{"gerysantoso", "winter2023123456"},
+		{"gerysantoso", "winter2023654321"},
+		{"nicholasdwiarto", "winter2023"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.SetBasicAuth(attempt[0], attempt[1])
+		handler(httptest.NewRecorder(), r)
+	}
+
+	if len(hashes) != 3 || hashes[0] == "" || hashes[0] != hashes[1] || hashes[0] != hashes[2] {
+		t.Errorf("Expected the same password to give the same hash whatever the second factor. Got: %v.", hashes)
+	}
+}
+
+// Tests that concurrent decisions never interleave their audit records.
+func TestAuditWriterConcurrency(t *testing.T) {
+	buf := &bytes.Buffer{}
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.AuditWriter = buf
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.SetBasicAuth("gerysantoso", "gerysantoso")
+			handler(httptest.NewRecorder(), r)
+		}()
+	}
+	wg.Wait()
+	auth.FlushAudit()
+
+	lines := 0
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		if !json.Valid(scanner.Bytes()) {
+			t.Fatalf("Expected a valid JSON line, got: %q.", scanner.Text())
+		}
+		lines++
+	}
+
+	if lines != 50 {
+		t.Errorf("Expected and actual number of audit records are different! Expected: %v. Got: %v.", 50, lines)
+	}
+}
+
+// blockingWriter is an audit writer which blocks until released, such as a stalled remote collector.
+type blockingWriter struct {
+	started chan struct{}
+	release chan struct{}
+	lines   int
+}
+
+// Write signals the first call, and blocks every call until released.
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	if w.lines == 0 {
+		close(w.started)
+	}
+	<-w.release
+	w.lines++
+
+	return len(p), nil
+}
+
+// Tests that a stalled audit writer never blocks the requests, and that the records which do not fit in the
+// queue are dropped and counted.
+func TestAuditWriterStalled(t *testing.T) {
+	writer := &blockingWriter{started: make(chan struct{}), release: make(chan struct{})}
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.AuditWriter = writer
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	send := func() {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.SetBasicAuth("gerysantoso", "gerysantoso")
+		handler(httptest.NewRecorder(), r)
+	}
+
+	// The first record is being written, and stalls the writer.
+	send()
+	<-writer.started
+
+	for i := 0; i < auditQueueSize+5; i++ {
+		send()
+	}
+
+	if dropped := auth.Stats().AuditDropped; dropped != 5 {
+		t.Errorf("Expected and actual dropped audit records are different! Expected: %v. Got: %v.", 5, dropped)
+	}
+
+	close(writer.release)
+	auth.FlushAudit()
+
+	if writer.lines != auditQueueSize+1 {
+		t.Errorf("Expected and actual number of audit records are different! Expected: %v. Got: %v.", auditQueueSize+1, writer.lines)
+	}
+	auth.ResetStats()
+	if dropped := auth.Stats().AuditDropped; dropped != 0 {
+		t.Errorf("Expected and actual dropped audit records are different! Expected: %v. Got: %v.", 0, dropped)
+	}
+}
@@ -0,0 +1,16 @@
+package basic
+
+import "net/http"
+
+// SendServiceUnavailableResponse is used to send back a response if the authenticator fails after the context of
+// the request is done, for example, because of a deadline or a graceful shutdown. The credentials may well be valid,
+// so `WWW-Authenticate` is not set.
+func (a *BasicAuth) SendServiceUnavailableResponse(w http.ResponseWriter, r *http.Request) {
+	a.stripHeaders(w)
+	if a.ServiceUnavailableResponse == nil {
+		(&AuthError{Code: http.StatusServiceUnavailable, Reason: serviceUnavailableMessage}).ServeHTTP(w, r)
+		return
+	}
+
+	a.ServiceUnavailableResponse.ServeHTTP(w, r)
+}
@@ -0,0 +1,236 @@
+package basic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Tests that `AuthenticatorCtx` receives the context of the request, and that a done context fails cleanly.
+func TestAuthenticatorCtx(t *testing.T) {
+	// A slow backend honoring the context, as a remote call would.
+	slow := func(ctx context.Context, username, password string) (bool, error) {
+		select {
+		case <-time.After(5 * time.Second):
+			return true, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+
+	tests := []struct {
+		name           string
+		authenticator  func(ctx context.Context, username, password string) (bool, error)
+		password       string
+		timeout        time.Duration
+		expectedStatus int
+	}{
+		{
+			name: "test_valid_credentials",
+			authenticator: func(ctx context.Context, username, password string) (bool, error) {
+				return password == "gerysantoso", nil
+			},
+			password:       "gerysantoso",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "test_invalid_credentials",
+			authenticator: func(ctx context.Context, username, password string) (bool, error) {
+				return password == "gerysantoso", nil
+			},
+			password:       "wrong_password",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "test_error_without_deadline",
+			authenticator: func(ctx context.Context, username, password string) (bool, error) {
+				return false, errors.New("backend is broken")
+			},
+			password:       "gerysantoso",
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "test_deadline_exceeded",
+			authenticator:  slow,
+			password:       "gerysantoso",
+			timeout:        10 * time.Millisecond,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Test", nil)
+			auth.AuthenticatorCtx = tc.authenticator
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth("gerysantoso", tc.password)
+
+			if tc.timeout > 0 {
+				ctx, cancel := context.WithTimeout(r.Context(), tc.timeout)
+				defer cancel()
+				r = r.WithContext(ctx)
+			}
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if challenge := w.Header().Get("WWW-Authenticate"); tc.expectedStatus == http.StatusServiceUnavailable && challenge != "" {
+				t.Errorf("Expected no challenge on an unavailable backend! Got: %v.", challenge)
+			}
+		})
+	}
+}
+
+// Tests that the context of the request reaches `AuthenticatorCtx`, and that it takes precedence over `AuthenticatorE`.
+func TestAuthenticatorCtxContext(t *testing.T) {
+	type key struct{}
+
+	auth := NewDefaultBasicAuth(nil)
+	auth.AuthenticatorE = func(username, password string) (bool, error) { return false, nil }
+	auth.AuthenticatorCtx = func(ctx context.Context, username, password string) (bool, error) {
+		return ctx.Value(key{}) == "tenant", nil
+	}
+
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	r.SetBasicAuth("gerysantoso", "gerysantoso")
+	r = r.WithContext(context.WithValue(r.Context(), key{}, "tenant"))
+
+	handler(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusOK, w.Code)
+	}
+
+	if stats := auth.Stats(); stats.Success != 1 {
+		t.Errorf("Expected and actual successes are different! Expected: %v. Got: %v.", 1, stats.Success)
+	}
+}
+
+// Tests that a custom `ServiceUnavailableResponse` is sent once the context of the request is cancelled.
+func TestServiceUnavailableResponse(t *testing.T) {
+	auth := NewDefaultBasicAuth(nil)
+	auth.ServiceUnavailableResponse = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	auth.AuthenticatorCtx = func(ctx context.Context, username, password string) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	}
+
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	r.SetBasicAuth("gerysantoso", "gerysantoso")
+
+	handler(w, r)
+
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected the custom response to be sent! Status: %v. Retry-After: %v.", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	if stats := auth.Stats(); stats.Errors != 1 {
+		t.Errorf("Expected and actual errors are different! Expected: %v. Got: %v.", 1, stats.Errors)
+	}
+}
+
+// Tests that an authenticator swapped in with `SetAuthenticatorCtx` receives the context of the request, outranks
+// `AuthenticatorCtx`, and is never cached.
+func TestSetAuthenticatorCtx(t *testing.T) {
+	type key struct{}
+
+	tests := []struct {
+		name           string
+		tenant         string
+		timeout        time.Duration
+		expectedStatus int
+		expectedCalls  int
+	}{
+		{
+			name:           "test_valid_context",
+			tenant:         "tenant",
+			expectedStatus: http.StatusOK,
+			expectedCalls:  2,
+		},
+		{
+			name:           "test_invalid_context",
+			tenant:         "other",
+			expectedStatus: http.StatusUnauthorized,
+			expectedCalls:  2,
+		},
+		{
+			name:           "test_deadline_exceeded",
+			tenant:         "tenant",
+			timeout:        time.Nanosecond,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCalls:  2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			auth := NewDefaultBasicAuth(nil)
+			auth.CacheTTL = time.Minute
+			auth.AuthenticatorCtx = func(ctx context.Context, username, password string) (bool, error) {
+				return false, nil
+			}
+			auth.SetAuthenticatorCtx(func(ctx context.Context, username, password string) (bool, error) {
+				calls++
+				if tc.timeout > 0 {
+					<-ctx.Done()
+					return false, ctx.Err()
+				}
+
+				return ctx.Value(key{}) == "tenant", nil
+			})
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			for i := 0; i < 2; i++ {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				w := httptest.NewRecorder()
+				r.SetBasicAuth("gerysantoso", "gerysantoso")
+				ctx := context.WithValue(r.Context(), key{}, tc.tenant)
+				if tc.timeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+					defer cancel()
+				}
+
+				handler(w, r.WithContext(ctx))
+
+				if tc.expectedStatus != w.Code {
+					t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+				}
+			}
+
+			if calls != tc.expectedCalls {
+				t.Errorf("Expected and actual calls are different! Expected: %v. Got: %v.", tc.expectedCalls, calls)
+			}
+
+			// Swapping back to `nil` falls back to `AuthenticatorCtx`.
+			auth.SetAuthenticatorCtx(nil)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth("gerysantoso", "gerysantoso")
+			handler(w, r.WithContext(context.WithValue(r.Context(), key{}, "tenant")))
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusUnauthorized, w.Code)
+			}
+		})
+	}
+}
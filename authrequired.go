@@ -0,0 +1,48 @@
+package basic
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// maxPeekedBodyLen is the maximum number of bytes of a body buffered for `AuthRequired`.
+const maxPeekedBodyLen = 1 << 20
+
+// readCloser combines the reader of a restored body with the closer of the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// authRequired checks whether a request has to be authenticated according to `AuthRequired`. The body is buffered
+// and handed to the predicate, then restored, so that the protected handler can still read it in full. The check
+// fails closed: authentication is required if the body is longer than 1 MiB, if it cannot be read, or if the predicate
+// returns an error.
+func (a *BasicAuth) authRequired(r *http.Request) bool {
+	if a.AuthRequired == nil {
+		return true
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		required, err := a.AuthRequired(r)
+		return required || err != nil
+	}
+
+	original := r.Body
+	buffered, err := io.ReadAll(io.LimitReader(original, maxPeekedBodyLen+1))
+	restore := func() {
+		r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(buffered), original), Closer: original}
+	}
+
+	if err != nil || len(buffered) > maxPeekedBodyLen {
+		restore()
+		return true
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(buffered))
+	required, err := a.AuthRequired(r)
+	restore()
+
+	return required || err != nil
+}
@@ -0,0 +1,100 @@
+package basic
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Tests that `AuthRequired` can skip authentication depending on the body, which is still read in full by the handler.
+func TestAuthRequired(t *testing.T) {
+	introspection := `{"query":"query IntrospectionQuery { __schema { types { name } } }"}`
+	mutation := `{"query":"mutation { deleteUser(id: 1) }"}`
+	oversized := `{"query":"query IntrospectionQuery { __schema { types { name } } }","padding":"` + strings.Repeat("a", maxPeekedBodyLen) + `"}`
+
+	tests := []struct {
+		name           string
+		body           string
+		credentials    bool
+		expectedStatus int
+	}{
+		{
+			name:           "test_public_operation",
+			body:           introspection,
+			credentials:    false,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_protected_operation_without_credentials",
+			body:           mutation,
+			credentials:    false,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_protected_operation_with_credentials",
+			body:           mutation,
+			credentials:    true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_predicate_error_fails_closed",
+			body:           `{"query":`,
+			credentials:    false,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_oversized_body_fails_closed",
+			body:           oversized,
+			credentials:    false,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_oversized_body_with_credentials",
+			body:           oversized,
+			credentials:    true,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "GraphQL", map[string]string{"gerysantoso": "gerysantoso"})
+			auth.AuthRequired = func(r *http.Request) (bool, error) {
+				var operation struct {
+					Query string `json:"query"`
+				}
+
+				if err := json.NewDecoder(r.Body).Decode(&operation); err != nil {
+					return false, err
+				}
+
+				return !strings.HasPrefix(operation.Query, "query IntrospectionQuery"), nil
+			}
+
+			var body string
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+				read, _ := io.ReadAll(r.Body)
+				body = string(read)
+				w.WriteHeader(http.StatusOK)
+			})
+			r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			if tc.credentials {
+				r.SetBasicAuth("gerysantoso", "gerysantoso")
+			}
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if w.Code == http.StatusOK && body != tc.body {
+				t.Errorf("Expected and actual bodies read by the handler are different! Expected: %v bytes. Got: %v bytes.", len(tc.body), len(body))
+			}
+		})
+	}
+}
@@ -21,27 +21,298 @@
 // `WWW-Authenticate` header is only sent if both `Charset` and `Realm` are set. `Users` attribute is a 1-to-1 mapping of username
 // and password.
 //
+// Basic Authentication is stateless: credentials are sent with, and verified on, every single request. This package keeps it
+// that way, as every request is authenticated independently against the configuration of the `BasicAuth` instance protecting
+// its route, even if it arrives on a keep-alive connection that previously carried other credentials or other realms. Nothing is
+// cached across requests, unless `ConnectionCache` is set, which only skips verifying the very same credentials again on the same
+// connection and for the same instance. `CoalesceAuthentications` only shares the verdict between identical requests which are in
+// flight at the same time.
+//
 // See example in `example/main.go`.
 package basic
 
 import (
-	"crypto/sha256"
+	"context"
+	"crypto/sha1"
 	"crypto/subtle"
-	"fmt"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"mime"
 	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default messages sent back to the client on failed authentications.
+const (
+	compromisedPasswordMessage  = "Password has been compromised, please change it!"
+	insecureTransportMessage    = "HTTPS is required!"
+	internalErrorMessage        = "Internal server error!"
+	invalidCredentialsMessage   = "Invalid username and/or password!"
+	invalidSchemeMessage        = "Invalid authentication scheme!"
+	methodNotAllowedMessage     = "Method not allowed!"
+	serviceUnavailableMessage   = "Authentication is temporarily unavailable!"
+	tooLongMessage              = "Username and/or password is too long!"
+	unsupportedMediaTypeMessage = "Unsupported content type!"
+)
+
+// result is the outcome of a single authentication attempt.
+type result int
+
+// All possible outcomes of an authentication attempt.
+const (
+	resultSuccess result = iota
+	resultMissingCredentials
+	resultInvalidScheme
+	resultInvalidCredentials
+	resultCompromisedPassword
+	resultUsernameTooLong
+	resultPasswordTooLong
+	resultChallengeFirst
+	resultUnsupportedMediaType
+	resultAuthenticatorError
+	resultIdleExpired
+	resultLockedOut
+	resultMethodNotAllowed
+	resultInsecureTransport
+	resultUnavailable
+	resultReplayedCode
 )
 
+// String returns the name of the outcome, used in audit records.
+func (res result) String() string {
+	switch res {
+	case resultSuccess:
+		return "success"
+	case resultMissingCredentials:
+		return "missing_credentials"
+	case resultInvalidScheme:
+		return "invalid_scheme"
+	case resultInvalidCredentials:
+		return "invalid_credentials"
+	case resultCompromisedPassword:
+		return "compromised_password"
+	case resultUsernameTooLong:
+		return "username_too_long"
+	case resultPasswordTooLong:
+		return "password_too_long"
+	case resultChallengeFirst:
+		return "challenge_first"
+	case resultUnsupportedMediaType:
+		return "unsupported_media_type"
+	case resultAuthenticatorError:
+		return "authenticator_error"
+	case resultIdleExpired:
+		return "idle_expired"
+	case resultLockedOut:
+		return "locked_out"
+	case resultMethodNotAllowed:
+		return "method_not_allowed"
+	case resultInsecureTransport:
+		return "insecure_transport"
+	case resultUnavailable:
+		return "unavailable"
+	case resultReplayedCode:
+		return "replayed_code"
+	default:
+		return "unknown"
+	}
+}
+
+// message returns the default message sent back to the client for the outcome.
+func (res result) message() string {
+	switch res {
+	case resultMissingCredentials, resultInvalidScheme, resultChallengeFirst:
+		return invalidSchemeMessage
+	case resultInvalidCredentials, resultIdleExpired, resultLockedOut, resultReplayedCode:
+		return invalidCredentialsMessage
+	case resultCompromisedPassword:
+		return compromisedPasswordMessage
+	case resultUsernameTooLong, resultPasswordTooLong:
+		return tooLongMessage
+	case resultUnsupportedMediaType:
+		return unsupportedMediaTypeMessage
+	case resultAuthenticatorError:
+		return internalErrorMessage
+	case resultMethodNotAllowed:
+		return methodNotAllowedMessage
+	case resultInsecureTransport:
+		return insecureTransportMessage
+	case resultUnavailable:
+		return serviceUnavailableMessage
+	default:
+		return ""
+	}
+}
+
+// status returns the default HTTP status code sent back to the client for the outcome.
+func (res result) status() int {
+	switch res {
+	case resultSuccess:
+		return http.StatusOK
+	case resultUsernameTooLong, resultPasswordTooLong:
+		return http.StatusBadRequest
+	case resultUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	case resultAuthenticatorError:
+		return http.StatusInternalServerError
+	case resultMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case resultInsecureTransport:
+		return http.StatusForbidden
+	case resultUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusUnauthorized
+	}
+}
+
+// AuthError represents a failed authentication. It implements both `error` and `http.Handler`, so custom
+// flows can either inspect it or send it straight back to the client with `ServeHTTP`.
+type AuthError struct {
+	Code      int    // HTTP status code to be sent back to the client.
+	Reason    string // Human-readable reason of the failure. This is also used as the response body.
+	Challenge string // Optional `WWW-Authenticate` challenge. Not sent if empty.
+}
+
+// Error returns the reason of the failed authentication.
+func (e *AuthError) Error() string {
+	return e.Reason
+}
+
+// ServeHTTP writes the challenge (if any), the status code, and the reason to the client. The challenge is sent in
+// `Proxy-Authenticate` with `407 Proxy Authentication Required`, and in `WWW-Authenticate` otherwise.
+func (e *AuthError) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if e.Challenge != "" && e.Code == http.StatusProxyAuthRequired {
+		w.Header().Set("Proxy-Authenticate", e.Challenge)
+	} else if e.Challenge != "" {
+		w.Header().Set("WWW-Authenticate", e.Challenge)
+	}
+
+	writeError(w, r, e.Reason, e.Code)
+}
+
+// writeError replies to the request with an error message and a status code, just like `http.Error`. As per
+// HTTP semantics, responses to `HEAD` requests only carry the status code and the headers, without any body.
+func writeError(w http.ResponseWriter, r *http.Request, message string, code int) {
+	if r.Method != http.MethodHead {
+		http.Error(w, message, code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+}
+
 // BasicAuth is used to configure all the library options.
 type BasicAuth struct {
-	Authenticator              func(username, password string) bool // Custom callback to find out the validity of a user's authentication process. This can be implemented in any implementation detail (for example: DB calls).
-	Charset                    string                               // Custom charset to be passed in the `WWW-Authenticate` header. According to RFC 7617, this has to be 'UTF-8'.
-	InvalidCredentialsResponse http.Handler                         // Callback to be invoked after receiving an InvalidCredentials error.
-	InvalidSchemeResponse      http.Handler                         // Callback to be invoked after receiving an InvalidScheme error.
-	Realm                      string                               // Specific realm for an authorization endpoint. This can be an arbitrary string.
-	Users                      map[string]string                    // Static credentials for all users. Can be `nil` if need be.
+	stats statsCounters // Counters behind `Stats`. Kept as the first field, so they are 64-bit aligned for atomic operations on 32-bit platforms.
+
+	AllowEmptyPassword           bool                                                               // Accept empty passwords, such as the ones of legacy service accounts. Defaults to false, so requests with an empty password are rejected as invalid credentials before calling any authenticator, even if the value of the user in `Users` is empty.
+	AuditPasswordHashes          bool                                                               // Opt-in: record a keyed hash of the password of failed attempts in the audit trail and `AuthEvent`, to spot the same password tried against many usernames. Hashes are truncated, and salted per process, so they are neither reversible with precomputed tables nor correlatable across processes.
+	AuditWriter                  io.Writer                                                          // Optional destination of the audit trail, where one JSON object is written per authentication decision. Records are written in the background, and dropped (see `Stats`) if the writer cannot keep up. Call `FlushAudit` before shutting down. Can be `nil` if need be.
+	AuthRequired                 func(r *http.Request) (bool, error)                                // Optional predicate deciding whether a request has to be authenticated, for example, depending on the operation of a GraphQL request. Up to 1 MiB of the body is buffered for it to read, and restored for the protected handler. Authentication is required if the body is longer, or if the predicate returns an error.
+	Authenticator                func(username, password string) bool                               // Custom callback to find out the validity of a user's authentication process. This can be implemented in any implementation detail (for example: DB calls). The constructors set it to the default authenticator, verifying `Users` with `PasswordVerifier`, which can be wrapped. `Users` are verified the same way if `nil`.
+	AuthenticatorCtx             func(ctx context.Context, username, password string) (bool, error) // Variant of `AuthenticatorE` receiving the context of the request, so its deadline and cancellation propagate to remote backends. Errors once the context is done are answered with `ServiceUnavailableResponse`. Takes precedence over `AuthenticatorE` and `Authenticator` if set, but not over `SetAuthenticator` and `SetAuthenticatorCtx`.
+	AuthenticatorE               func(username, password string) (bool, error)                      // Variant of `Authenticator` that can fail, for example, if a database is unreachable. Errors are answered with `InternalErrorResponse` instead of a challenge. Takes precedence over `Authenticator` if set, but not over `SetAuthenticator` and `SetAuthenticatorCtx`.
+	AuthenticatorWithRequest     func(r *http.Request, username, password string) bool              // Request-aware variant of `Authenticator` to scope credentials by path, client IP, or tenant header. Takes precedence over `Authenticator` if set, but not over `SetAuthenticator` and `SetAuthenticatorCtx`.
+	BatchBypassThrottling        bool                                                               // Verify the credentials of locked out usernames (see `MaxFailures`) in `VerifyBatch`, for administrative checks. Batch verifications never count as failed attempts either way.
+	BatchConcurrency             int                                                                // Maximum number of credentials verified at the same time by `VerifyBatch`. Defaults to `runtime.GOMAXPROCS(0)` if zero or negative.
+	CacheSize                    int                                                                // Maximum number of successful authentications remembered by `CacheTTL`, after which the least recently used ones are evicted. Defaults to 1024 if zero.
+	CacheTTL                     time.Duration                                                      // Opt-in cache of successful authentications for slow authenticators (such as LDAP): if positive, requests with the same credentials skip the authenticator for this long. Failures are never cached. See `ClearCache`.
+	ChallengeFirstWindow         time.Duration                                                      // Opt-in, stateful mode for kiosks: if positive, the first request of each client IP is always challenged to force the login dialog, and accepted normally for this long afterwards.
+	ChallengeParams              map[string]string                                                  // Extra non-standard auth-params appended to the challenge (for example, `scope`) for custom tooling. Values are escaped, and invalid names are skipped.
+	Charset                      string                                                             // Custom charset to be passed in the `WWW-Authenticate` header. According to RFC 7617, this has to be 'UTF-8'. 'ISO-8859-1' is supported for legacy clients: credentials are converted to UTF-8, and the charset is left out of the challenge.
+	BearerFallback               func(token string) bool                                            // Optional verification of `Bearer` tokens (RFC 6750), accepted instead of Basic credentials if it returns true. The challenge then advertises both schemes. Tokens should be compared in constant time, such as with `CompareInputs`.
+	BrowserLoginPage             []byte                                                             // Optional HTML page sent with challenges to clients accepting `text/html`, instead of the configured failure responses. API clients are not affected.
+	CoalesceAuthentications      bool                                                               // Share a single authenticator call between concurrent requests carrying identical credentials. Useful for slow backends. A waiting request gives up with `ServiceUnavailableResponse` once its context is done.
+	CompromisedPasswordResponse  http.Handler                                                       // Callback to be invoked after receiving a password listed in `CompromisedPasswords`. Falls back to `InvalidCredentialsResponse` if `nil`.
+	CompromisedPasswords         map[string]struct{}                                                // Opt-in set of uppercase hex SHA-1 hashes of known-breached passwords (the format of downloadable breach lists). Can be `nil` if need be.
+	ConnectionCache              bool                                                               // Opt-in cache of the credentials verified on each keep-alive connection, so bursts of requests are not verified again. Requires `ConnContext` to be set on the `http.Server`.
+	ConnectionCacheMaxAge        time.Duration                                                      // Maximum age of the credentials verified with `ConnectionCache`, after which they are verified again. Zero means as long as the connection lives.
+	CredentialEncoding           *base64.Encoding                                                   // Non-conformant escape hatch to decode credentials of exotic clients with a custom Base64 alphabet. Defaults to `base64.StdEncoding` if `nil`.
+	CredentialVersion            func(username string) string                                       // Optional version of the stored credentials of a user, such as an ETag or an update timestamp of a database. Credentials verified with `ConnectionCache` are verified again once it changes, so password rotations apply immediately.
+	ForwardedProtoHeader         string                                                             // Header set by a trusted reverse proxy terminating TLS, such as `X-Forwarded-Proto`. With `RequireTLS`, requests where it is `https` are accepted. Only set it if the proxy always overwrites the header, as clients can send it too.
+	IdleTimeout                  time.Duration                                                      // Opt-in, stateful, best-effort mode for sensitive panels: if positive, clients idle for this long are challenged again with a changed realm, so browsers prompt for credentials again. See `SendIdleExpiredResponse`.
+	DeniedUsers                  []string                                                           // Usernames that can never be authenticated, whatever the authenticator or `Users` say, such as `root` or disabled accounts. They are compared with the normalized username in constant time.
+	HeaderName                   string                                                             // Header carrying the credentials, such as `X-Original-Authorization` behind some proxies. Case-insensitive. Defaults to `Authorization` if empty.
+	InsecureTransportResponse    http.Handler                                                       // Callback to be invoked after receiving a request over plaintext HTTP while `RequireTLS` is set. Defaults to `403 Forbidden` if `nil`.
+	InternalErrorResponse        http.Handler                                                       // Callback to be invoked after an error of `AuthenticatorE`, or after recovering from a panic in the protected handler (see `RecoverNext`).
+	InvalidCredentialsResponse   http.Handler                                                       // Callback to be invoked after receiving an InvalidCredentials error.
+	InvalidCredentialsStatus     int                                                                // Status code of the default `InvalidCredentialsResponse`, such as `403 Forbidden` for gateways expecting it. Defaults to `401 Unauthorized` if zero. Custom responses are not affected.
+	InvalidRequestResponse       http.Handler                                                       // Callback to be invoked after receiving a username or a password longer than allowed.
+	InvalidSchemeResponse        http.Handler                                                       // Callback to be invoked after receiving an InvalidScheme error.
+	InvalidSchemeStatus          int                                                                // Status code of the default `InvalidSchemeResponse`. Defaults to `401 Unauthorized` if zero. Custom responses are not affected.
+	LenientPercentDecode         bool                                                               // Non-conformant workaround for broken proxies that percent-encode the `Authorization` header. If set, a header that cannot be parsed is unescaped and parsed again. Defaults to `false`.
+	LockoutDuration              time.Duration                                                      // Duration of the lockout of a username after `MaxFailures` failed attempts, and the window in which failures are counted.
+	Logger                       func(event AuthEvent)                                              // Optional hook called with every authentication attempt, for example, to write structured logs. Passwords are never passed. Must be safe for concurrent use.
+	MaxCredentialLength          int                                                                // Maximum length in bytes of the `Authorization` header, checked before decoding it. Longer headers are answered with `InvalidSchemeResponse`. Defaults to 8 KiB with `NewDefaultBasicAuth`. Zero means unlimited.
+	MaxFailures                  int                                                                // Opt-in brute-force protection: if positive along with `LockoutDuration`, a username is locked out after this many consecutive failed attempts, without calling the authenticator.
+	MaxPasswordLen               int                                                                // Maximum length of the decoded password in bytes. Zero means unlimited.
+	MaxUsernameLen               int                                                                // Maximum length of the decoded username in bytes. Zero means unlimited.
+	MethodNotAllowedResponse     http.Handler                                                       // Callback to be invoked after receiving an unsafe method while `ReadOnly` is set. Defaults to `405 Method Not Allowed` if `nil`.
+	Metrics                      Metrics                                                            // Optional receiver of every authentication decision, to plug in any metrics system. Can be `nil` if need be.
+	MissingCredentialsResponse   http.Handler                                                       // Callback to be invoked if the request has no `Authorization` header at all, such as on a first visit. Falls back to `InvalidSchemeResponse` if `nil`.
+	PasswordComparator           func(supplied, stored string) bool                                 // Optional function used by the default authenticator to compare passwords with the values of `Users`, such as a bcrypt or argon2 comparison. Defaults to `CompareInputs` if `nil`. Ignored if `PasswordVerifier` is set, which can also report errors.
+	PasswordPolicy               PasswordPolicy                                                     // Complexity requirements for the plaintext passwords passed to `AddUser`. Only checked when users are added, never when requests are authenticated. The zero value accepts any password.
+	PasswordUnicodeNormalizer    func(password string) string                                       // Optional Unicode normalization (such as NFC, see `basicunicode`) of the supplied password, and of the stored one if it is plaintext (neither `PasswordVerifier` nor `PasswordComparator` is set), in the default authenticator. It runs before the constant-time comparison, and is not constant-time itself.
+	PasswordVerifier             PasswordVerifier                                                   // Verifier used by the default authenticator to compare passwords with the values of `Users`. Defaults to `PasswordComparator`, then to `PlaintextVerifier`, if `nil`.
+	ProxyMode                    bool                                                               // Act as a forward proxy (RFC 7235): read the credentials from `Proxy-Authorization` unless `HeaderName` is set, and answer challenges with `407 Proxy Authentication Required` and `Proxy-Authenticate`.
+	ReadOnly                     bool                                                               // Reject requests with methods not listed in `SafeMethods` with `MethodNotAllowedResponse` before authenticating, such as for read-only mirrors.
+	Realm                        string                                                             // Specific realm for an authorization endpoint. This can be an arbitrary string.
+	RealmFunc                    func(r *http.Request) string                                       // Optional realm of each request (for example, by path prefix), so a single instance can scope the credential prompts of browsers per route group. Falls back to `Realm` if it returns an empty string.
+	RecoverNext                  bool                                                               // Recover from panics in the protected handler and send `InternalErrorResponse` instead. Off by default.
+	RequireContentTypes          []string                                                           // Opt-in allowlist of media types (such as `application/json`) for the bodies of POST, PUT, and PATCH requests, checked before authenticating. Others are rejected with `UnsupportedMediaTypeResponse`.
+	RequireTLS                   bool                                                               // Reject requests received over plaintext HTTP with `InsecureTransportResponse` before reading their credentials, so misconfigured deployments fail loudly instead of leaking credentials.
+	RequestIDs                   bool                                                               // Opt-in correlation IDs: the `X-Request-ID` of the request (or a generated one) is sent back on failure responses, recorded in the audit trail, and exposed with `RequestIDFromContext`.
+	SafeMethods                  []string                                                           // Methods considered safe by `ReadOnly` and `AuthenticateMethods`. Defaults to `GET`, `HEAD`, `OPTIONS`, and `TRACE` if `nil`, and can be extended with read methods of WebDAV such as `PROPFIND`.
+	SlowAuthThreshold            time.Duration                                                      // Opt-in diagnosis of slow backends: if positive, authentications taking longer than this are passed to `Logger` with `Slow` and `Elapsed` set, and written to `AuditWriter` as warnings with their duration.
+	ServiceUnavailableResponse   http.Handler                                                       // Callback to be invoked if the authenticator fails after the context of the request is done, for example, on a timeout or a shutdown. Defaults to `503 Service Unavailable` if `nil`.
+	StripHeadersOnFailure        []string                                                           // Headers to be removed from failure responses, such as identifying headers set by previous middlewares.
+	TOTPDigits                   int                                                                // Number of digits of the TOTP codes appended to the passwords of users in `UserTOTP`. Defaults to 6.
+	TOTPSkew                     int                                                                // Number of 30-second steps accepted before and after the current one to tolerate clock drift. Defaults to 1.
+	TokenEndpoint                string                                                             // Optional URL where clients can exchange their credentials for a token, advertised in a `Link` header with the `token-endpoint` relation on challenges. Characters not allowed in a URI are percent-encoded.
+	TrimStoredPasswords          bool                                                               // Opt-in: remove the leading and trailing white space of the values of `Users` before verifying them in the default authenticator, such as the trailing newline of passwords loaded from files or secrets. `Validate` reports such values if it is not set.
+	UnsupportedMediaTypeResponse http.Handler                                                       // Callback to be invoked after receiving a body with a media type not listed in `RequireContentTypes`. Defaults to `415 Unsupported Media Type` if `nil`.
+	UserTOTP                     map[string]string                                                  // Opt-in per-user base32 TOTP secrets (RFC 6238). Users listed here have to append their current code to their password.
+	UsernameCaseFold             bool                                                               // Step 2 of the username normalization: fold the case of usernames, so that they are case-insensitive. The keys of `Users` have to be lowercase.
+	UsernamePattern              *regexp.Regexp                                                     // Step 5 of the username normalization: opt-in pattern that normalized usernames have to match, such as an email format. Other usernames are rejected as invalid credentials without reaching the authenticator.
+	UsernameTransform            func(username string) string                                       // Step 4 of the username normalization: custom transformation, such as appending a default domain suffix. Skipped if `nil`.
+	UsernameTrimSpace            bool                                                               // Step 1 of the username normalization: remove leading and trailing white space from usernames.
+	UsernameUnicodeNormalizer    func(username string) string                                       // Step 3 of the username normalization: Unicode normalization, such as `norm.NFC.String` of `golang.org/x/text/unicode/norm`. Skipped if `nil`.
+	Users                        map[string]string                                                  // Static credentials for all users. Can be `nil` if need be. Once serving, use `AddUser` and `RemoveUser` to modify it.
+
+	auditMu              sync.Mutex                           // Guards `auditQueue`, `auditWriting`, and `auditCond`.
+	auditQueue           [][]byte                             // Audit records waiting to be written to `AuditWriter`.
+	auditWriting         bool                                 // Whether `writeAudit` is running.
+	auditCond            *sync.Cond                           // Signalled once `writeAudit` has emptied the queue.
+	swapped              atomic.Value                         // Authenticator set at runtime with `SetAuthenticator` or `SetAuthenticatorCtx`, stored as a `swappedAuthenticator`.
+	debugCredentialSink  func(username, password string)      // Test-only hook receiving the extracted credentials. Must never be set in production, as it sees plaintext passwords.
+	challengedMu         sync.Mutex                           // Guards `challenged`.
+	challenged           map[string]time.Time                 // Time of the forced challenge of each client IP, used if `ChallengeFirstWindow` is set.
+	flights              flightGroup                          // In-flight authenticator calls, used if `CoalesceAuthentications` is set.
+	results              resultCache                          // Successful authentications, cached if `CacheTTL` is set.
+	clock                func() time.Time                     // Source of the current time. Defaults to `time.Now` if `nil`.
+	totpMu               sync.Mutex                           // Guards `totpLastUsed`.
+	totpLastUsed         map[string]totpUse                   // Last accepted TOTP step of each user, used to reject replayed codes.
+	generation           uint32                               // Incremented by `SetAuthenticator`, `SetAuthenticatorCtx`, `AddUser`, and `RemoveUser` to invalidate the credentials cached with `ConnectionCache` or `CacheTTL`. Only accessed atomically.
+	idleMu               sync.Mutex                           // Guards `lastSeen`.
+	lastSeen             map[string]time.Time                 // Time of the last successful request of each client IP, used if `IdleTimeout` is set.
+	lockoutMu            sync.Mutex                           // Guards `lockouts`.
+	lockouts             map[string]lockoutEntry              // Recent failed attempts of each username, used if `MaxFailures` is set.
+	usersMu              sync.RWMutex                         // Guards `Users` against `AddUser` and `RemoveUser`.
+	deniedMu             sync.RWMutex                         // Guards `denied`.
+	denied               map[string]struct{}                  // Keys of the credentials revoked with `DenyCredential`.
+	defaultAuthenticator func(username, password string) bool // The default authenticator stored in `Authenticator` by the constructors, to tell whether it has been replaced.
 }
 
-// NewCustomBasicAuth is used to set up Basic Auth options with customizable configurations.
+// NewCustomBasicAuth is used to set up Basic Auth options with customizable configurations. `nil` handlers and
+// authenticators keep their defaults. Prefer `New`, which does not depend on the order of the arguments.
 func NewCustomBasicAuth(
 	authenticator func(username, password string) bool,
 	charset string,
@@ -50,126 +321,674 @@ func NewCustomBasicAuth(
 	realm string,
 	users map[string]string,
 ) *BasicAuth {
-	// Populate parameters with default values for several necessary attributes.
-	defaultConfig := NewDefaultBasicAuth(users)
-	if authenticator == nil {
-		authenticator = defaultConfig.Authenticator
+	return New(
+		WithAuthenticator(authenticator),
+		WithCharset(charset),
+		WithInvalidCredentialsResponse(invalidCredentialsResponse),
+		WithInvalidSchemeResponse(invalidSchemeResponse),
+		WithRealm(realm),
+		WithUsers(users),
+	)
+}
+
+// NewDefaultBasicAuth is used to set up Basic Auth options with default configurations.
+func NewDefaultBasicAuth(users map[string]string) *BasicAuth {
+	auth := &BasicAuth{
+		// RFC 7617: Only accept `UTF-8`.
+		Charset: "UTF-8",
+
+		// Response that will be sent if the password is known to be compromised.
+		CompromisedPasswordResponse: &AuthError{Code: http.StatusUnauthorized, Reason: compromisedPasswordMessage},
+
+		// Response that will be sent if the protected handler panics and `RecoverNext` is set.
+		InternalErrorResponse: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeError(w, r, internalErrorMessage, http.StatusInternalServerError)
+		}),
+
+		// Response that will be sent if the username or the password is too long.
+		InvalidRequestResponse: &AuthError{Code: http.StatusBadRequest, Reason: tooLongMessage},
+
+		// Oversized headers are rejected before being decoded.
+		MaxCredentialLength: defaultMaxCredentialLength,
+
+		// Custom realm in the authentication process.
+		Realm: "",
+
+		// Time-based one-time passwords are six digits long, with one step of tolerance for clock drift.
+		TOTPDigits: 6,
+		TOTPSkew:   1,
+
+		// List of users allowed to access the endpoint.
+		Users: users,
 	}
 
-	if invalidCredentialsResponse == nil {
-		invalidCredentialsResponse = defaultConfig.InvalidCredentialsResponse
+	// Accepts username and password. If the list of users is populated, the function will
+	// check whether the username exists and then tries to securely compare the passwords. If the list of users
+	// does not exist / has the length of zero, the function will return false. A closure of its own is kept, so
+	// `Validate` can tell whether it has been replaced.
+	auth.defaultAuthenticator = func(username, password string) bool {
+		return auth.verifyUser(username, password)
 	}
+	auth.Authenticator = auth.defaultAuthenticator
 
-	if invalidSchemeResponse == nil {
-		invalidSchemeResponse = defaultConfig.InvalidSchemeResponse
+	// Responses that will be sent if the credentials or the scheme (header) are invalid, with the configured status
+	// codes (`401 Unauthorized` by default).
+	auth.InvalidCredentialsResponse = http.HandlerFunc(auth.defaultInvalidCredentialsResponse)
+	auth.InvalidSchemeResponse = http.HandlerFunc(auth.defaultInvalidSchemeResponse)
+
+	return auth
+}
+
+// defaultInvalidCredentialsResponse is the default `InvalidCredentialsResponse`, sent with `InvalidCredentialsStatus`.
+func (a *BasicAuth) defaultInvalidCredentialsResponse(w http.ResponseWriter, r *http.Request) {
+	(&AuthError{Code: statusOrUnauthorized(a.InvalidCredentialsStatus), Reason: invalidCredentialsMessage}).ServeHTTP(w, r)
+}
+
+// defaultInvalidSchemeResponse is the default `InvalidSchemeResponse`, sent with `InvalidSchemeStatus`.
+func (a *BasicAuth) defaultInvalidSchemeResponse(w http.ResponseWriter, r *http.Request) {
+	(&AuthError{Code: statusOrUnauthorized(a.InvalidSchemeStatus), Reason: invalidSchemeMessage}).ServeHTTP(w, r)
+}
+
+// statusOrUnauthorized returns the configured status code, or `401 Unauthorized` if it is not set.
+func statusOrUnauthorized(status int) int {
+	if status == 0 {
+		return http.StatusUnauthorized
 	}
 
-	if len(users) == 0 {
-		users = defaultConfig.Users
+	return status
+}
+
+// NewAPIKeyAuth is used to set up Basic Auth options for services that use Basic Authentication as an API key carrier.
+// Any username is accepted, and only the password is compared (in constant time) against the shared secret.
+func NewAPIKeyAuth(secret string) *BasicAuth {
+	auth := NewDefaultBasicAuth(nil)
+	auth.Authenticator = func(username, password string) bool {
+		return CompareInputs(password, secret)
 	}
 
-	return &BasicAuth{
-		Authenticator:              authenticator,
-		Charset:                    charset,
-		InvalidCredentialsResponse: invalidCredentialsResponse,
-		InvalidSchemeResponse:      invalidSchemeResponse,
-		Realm:                      realm,
-		Users:                      users,
+	return auth
+}
+
+// SendInvalidCredentialsResponse is used to send back an invalid response if the
+// Basic Authorization credentials are invalid. The middleware sets `Retry-After` beforehand if the user is locked out.
+func (a *BasicAuth) SendInvalidCredentialsResponse(w http.ResponseWriter, r *http.Request) {
+	a.sendChallenge(w, r, a.InvalidCredentialsResponse, a.ChallengeFor(r))
+}
+
+// swappedAuthenticator wraps an authenticator set at runtime, as `atomic.Value` cannot store `nil` values. It is stored
+// with the signature of `AuthenticatorCtx`, and `contextAware` tells whether it was set with `SetAuthenticatorCtx`.
+type swappedAuthenticator struct {
+	authenticator func(ctx context.Context, username, password string) (bool, error)
+	contextAware  bool
+}
+
+// SetAuthenticator atomically swaps the authenticator at runtime, which is useful to migrate credential backends
+// (for example, from static users to LDAP) without restarting. In-flight requests either use the old or the new
+// authenticator, never a mix of both. Once set, it takes precedence over every configured authenticator
+// (`AuthenticatorWithRequest`, `AuthenticatorCtx`, `AuthenticatorE`, and `Authenticator`), which must not be modified
+// concurrently with requests, so that swapping always has an effect. Only an authenticator stored in the request
+// context with `ContextWithAuthenticator` still wins. Setting `nil` falls back to the configured authenticators again.
+func (a *BasicAuth) SetAuthenticator(authenticator func(username, password string) bool) {
+	var swapped swappedAuthenticator
+	if authenticator != nil {
+		swapped.authenticator = func(ctx context.Context, username, password string) (bool, error) {
+			return authenticator(username, password), nil
+		}
 	}
+
+	a.swap(swapped)
 }
 
-// NewDefaultBasicAuth is used to set up Basic Auth options with default configurations.
-func NewDefaultBasicAuth(users map[string]string) *BasicAuth {
-	return &BasicAuth{
-		// Accepts username and password. If the list of users is populated, the function will
-		// check whether the username exists and then tries to securely compare the passwords. If the list of users
-		// does not exist / has the length of zero, the function will return false.
-		Authenticator: func(username, password string) bool {
-			if len(users) != 0 {
-				if val, ok := users[username]; ok {
-					// Small trick to prevent timing attacks by hashing passwords before comparing
-					// them. This has its own overhead, but completely prevents timing attacks.
-					return CompareInputs(password, val)
-				}
+// SetAuthenticatorCtx is the variant of `SetAuthenticator` swapping in an authenticator with the signature of
+// `AuthenticatorCtx`, which receives the context of the request and whose errors are answered the same way. As with
+// `AuthenticatorCtx`, its verifications are neither coalesced nor cached. Setting `nil` falls back to the configured
+// authenticators again, and either setter replaces the authenticator set by the other.
+func (a *BasicAuth) SetAuthenticatorCtx(authenticator func(ctx context.Context, username, password string) (bool, error)) {
+	a.swap(swappedAuthenticator{authenticator: authenticator, contextAware: true})
+}
+
+// swap stores an authenticator set at runtime, and invalidates the credentials verified by the previous one.
+func (a *BasicAuth) swap(swapped swappedAuthenticator) {
+	a.swapped.Store(swapped)
+	atomic.AddUint32(&a.generation, 1)
+}
+
+// loadSwapped returns the authenticator set at runtime with `SetAuthenticator` or `SetAuthenticatorCtx`, if any.
+func (a *BasicAuth) loadSwapped() (swappedAuthenticator, bool) {
+	swapped, ok := a.swapped.Load().(swappedAuthenticator)
+
+	return swapped, ok && swapped.authenticator != nil
+}
+
+// configuredAuthenticatorE returns `AuthenticatorE` if set, `Authenticator` otherwise, and finally the default
+// authenticator verifying `Users` if neither is set. Only `AuthenticatorE` may fail.
+func (a *BasicAuth) configuredAuthenticatorE() func(username, password string) (bool, error) {
+	if a.AuthenticatorE != nil {
+		return a.AuthenticatorE
+	}
+
+	if a.Authenticator != nil {
+		return infallible(a.Authenticator)
+	}
+
+	return infallible(a.verifyUser)
+}
+
+// infallible adapts an authenticator which never fails to the signature of `AuthenticatorE`.
+func infallible(authenticator func(username, password string) bool) func(username, password string) (bool, error) {
+	return func(username, password string) (bool, error) {
+		return authenticator(username, password), nil
+	}
+}
+
+// contextAuthenticator returns the authenticator stored in a context with `ContextWithAuthenticator`, if any.
+func contextAuthenticator(ctx context.Context) (func(username, password string) bool, bool) {
+	authenticator, ok := ctx.Value(authenticatorContextKey{}).(func(username, password string) bool)
+
+	return authenticator, ok && authenticator != nil
+}
+
+// authenticatorFor picks the single authenticator called for a request. In order of precedence, it is:
+//
+//  1. the authenticator stored in the request context with `ContextWithAuthenticator`,
+//  2. the authenticator set at runtime with `SetAuthenticator` or `SetAuthenticatorCtx`,
+//  3. `AuthenticatorWithRequest`,
+//  4. `AuthenticatorCtx`, with the context of the request,
+//  5. `AuthenticatorE`,
+//  6. `Authenticator`,
+//  7. the default authenticator, verifying `Users` with `PasswordVerifier`.
+//
+// Identical concurrent verifications are coalesced, and successful ones are cached, if requested, but neither with
+// the first, the third, the fourth, nor one set with `SetAuthenticatorCtx`, as requests with their own authenticators
+// or contexts may not share a verdict.
+func (a *BasicAuth) authenticatorFor(r *http.Request) func(username, password string) (bool, error) {
+	if authenticator, ok := contextAuthenticator(r.Context()); ok {
+		return infallible(authenticator)
+	}
+
+	// The swapped authenticator is loaded once, so that a concurrent swap cannot skip over the other ones.
+	var authenticator func(username, password string) (bool, error)
+	if swapped, ok := a.loadSwapped(); ok {
+		authenticator = func(username, password string) (bool, error) {
+			return swapped.authenticator(r.Context(), username, password)
+		}
+		if swapped.contextAware {
+			return authenticator
+		}
+	} else {
+		if a.AuthenticatorWithRequest != nil {
+			return func(username, password string) (bool, error) {
+				return a.AuthenticatorWithRequest(r, username, password), nil
 			}
+		}
 
-			return false
-		},
+		if a.AuthenticatorCtx != nil {
+			return func(username, password string) (bool, error) {
+				return a.AuthenticatorCtx(r.Context(), username, password)
+			}
+		}
 
-		// RFC 7617: Only accept `UTF-8`.
-		Charset: "UTF-8",
+		authenticator = a.configuredAuthenticatorE()
+	}
 
-		// Response that will be sent if the credentials are invalid.
-		InvalidCredentialsResponse: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.Error(w, "Invalid username and/or password!", http.StatusUnauthorized)
-		}),
+	if a.CoalesceAuthentications {
+		coalesced := authenticator
+		authenticator = func(username, password string) (bool, error) {
+			return a.flights.do(r.Context(), credentialsKey(username, password), func() (bool, error) {
+				return coalesced(username, password)
+			})
+		}
+	}
 
-		// Response that will be sent if the scheme (header) is invalid.
-		InvalidSchemeResponse: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.Error(w, "Invalid authentication scheme!", http.StatusUnauthorized)
-		}),
+	if a.CacheTTL > 0 {
+		authenticator = a.cachedAuthenticator(authenticator)
+	}
 
-		// Custom realm in the authentication process.
-		Realm: "",
+	return authenticator
+}
 
-		// List of users allowed to access the endpoint.
-		Users: users,
+// SendCompromisedPasswordResponse is used to send back an invalid response if the password
+// is listed in the known-breached passwords. Uses the invalid credentials response if not set.
+func (a *BasicAuth) SendCompromisedPasswordResponse(w http.ResponseWriter, r *http.Request) {
+	if a.CompromisedPasswordResponse == nil {
+		a.SendInvalidCredentialsResponse(w, r)
+		return
 	}
+
+	a.sendChallenge(w, r, a.CompromisedPasswordResponse, a.ChallengeFor(r))
 }
 
-// SendInvalidCredentialsResponse is used to send back an invalid response if the
-// Basic Authorization credentials are invalid.
-func (a *BasicAuth) SendInvalidCredentialsResponse(w http.ResponseWriter, r *http.Request) {
-	a.SetWWWAuthenticate(w)
-	a.InvalidCredentialsResponse.ServeHTTP(w, r)
+// SendInternalErrorResponse is used to send back an error response if `AuthenticatorE` fails, or
+// if the protected handler panics while `RecoverNext` is set.
+func (a *BasicAuth) SendInternalErrorResponse(w http.ResponseWriter, r *http.Request) {
+	a.InternalErrorResponse.ServeHTTP(w, r)
+}
+
+// SendMissingCredentialsResponse is used to send back a response if the request does not carry
+// any credentials. Uses the invalid scheme response if not set.
+func (a *BasicAuth) SendMissingCredentialsResponse(w http.ResponseWriter, r *http.Request) {
+	if a.MissingCredentialsResponse == nil {
+		a.SendInvalidSchemeResponse(w, r)
+		return
+	}
+
+	a.sendChallenge(w, r, a.MissingCredentialsResponse, a.ChallengeFor(r))
+}
+
+// SendInvalidRequestResponse is used to send back an invalid response if the username or the
+// password is longer than allowed. This is not a challenge, so `WWW-Authenticate` is not set.
+func (a *BasicAuth) SendInvalidRequestResponse(w http.ResponseWriter, r *http.Request) {
+	a.stripHeaders(w)
+	a.InvalidRequestResponse.ServeHTTP(w, r)
 }
 
 // SendInvalidSchemeResponse is used to send back invalid response if the Basic
 // Authorization header is not in the proper format.
 func (a *BasicAuth) SendInvalidSchemeResponse(w http.ResponseWriter, r *http.Request) {
-	a.SetWWWAuthenticate(w)
-	a.InvalidSchemeResponse.ServeHTTP(w, r)
+	a.sendChallenge(w, r, a.InvalidSchemeResponse, a.ChallengeFor(r))
+}
+
+// SendUnsupportedMediaTypeResponse is used to send back a response if the media type of the request body is
+// not listed in `RequireContentTypes`. This is not a challenge, so `WWW-Authenticate` is not set.
+func (a *BasicAuth) SendUnsupportedMediaTypeResponse(w http.ResponseWriter, r *http.Request) {
+	a.stripHeaders(w)
+	if a.UnsupportedMediaTypeResponse == nil {
+		(&AuthError{Code: http.StatusUnsupportedMediaType, Reason: unsupportedMediaTypeMessage}).ServeHTTP(w, r)
+		return
+	}
+
+	a.UnsupportedMediaTypeResponse.ServeHTTP(w, r)
+}
+
+// now returns the current time from the configured clock.
+func (a *BasicAuth) now() time.Time {
+	if a.clock != nil {
+		return a.clock()
+	}
+
+	return time.Now()
+}
+
+// stripHeaders removes the headers listed in `StripHeadersOnFailure` from a failure response. Headers written
+// by the server itself after the handler returns (such as `Date`) cannot be removed this way.
+func (a *BasicAuth) stripHeaders(w http.ResponseWriter) {
+	for _, header := range a.StripHeadersOnFailure {
+		w.Header().Del(header)
+	}
 }
 
 // SetWWWAuthenticate sets the `WWW-Authenticate` network header on the API response payload. If the
-// charset and realm are both empty, we do not set the `WWW-Authenticate` header.
+// charset and realm are both empty, we do not set the `WWW-Authenticate` header. There is no request to pass to
+// `RealmFunc`, so the static `Realm` is used. Use `ChallengeFor` to get the challenge of a request.
 func (a *BasicAuth) SetWWWAuthenticate(w http.ResponseWriter) {
-	if a.Realm != "" && a.Charset != "" {
-		realm := fmt.Sprintf(`Basic realm="%s", charset="%s"`, a.Realm, a.Charset)
-		w.Header().Set("WWW-Authenticate", realm)
+	if challenge := a.challenge(); challenge != "" {
+		w.Header().Set("WWW-Authenticate", challenge)
+	}
+}
+
+// authenticate performs the authentication process on a request, records it, and returns the username with its outcome.
+func (a *BasicAuth) authenticate(r *http.Request) (string, result) {
+	start := time.Now()
+	username, res := a.check(r)
+
+	switch res {
+	case resultSuccess:
+		a.resetFailures(username)
+	case resultInvalidCredentials:
+		// Rejected bearer tokens have no username, and are not counted, so they neither lock out the empty username
+		// nor grow the failures tracked for lockouts.
+		if username != "" {
+			a.recordFailure(username)
+		}
+	}
+
+	// The duration is measured once, for the metrics and for the warnings about slow authentications.
+	elapsed := time.Since(start)
+	a.stats.count(res)
+	a.observe(res, elapsed)
+	// The audit trail and the logger are given the same time of the decision, from the clock of the instance.
+	decided := a.now()
+	passwordHash := a.attemptedPasswordHash(r, res)
+	a.audit(r, decided, username, passwordHash, res, elapsed)
+	a.log(r, decided, username, passwordHash, res, elapsed)
+
+	return username, res
+}
+
+// check decides the outcome of the authentication process on a request. The username is returned
+// (if it could be parsed) so the decision can be recorded.
+func (a *BasicAuth) check(r *http.Request) (string, result) {
+	// Refuse plaintext HTTP before reading anything, as the credentials may already have leaked.
+	if a.isInsecure(r) {
+		return "", resultInsecureTransport
+	}
+
+	// Cheaply reject methods and bodies that the protected handler does not accept before spending any effort on them.
+	if a.ReadOnly && !a.isSafeMethod(r.Method) {
+		return "", resultMethodNotAllowed
+	}
+
+	if !a.acceptsContentType(r) {
+		return "", resultUnsupportedMediaType
+	}
+
+	// Force the login dialog on the first request of a client if requested, even if it has credentials.
+	if a.challengeFirst(clientIP(r)) {
+		return "", resultChallengeFirst
+	}
+
+	// Challenge clients again after they have been idle for too long, even if they have valid credentials.
+	if a.idleExpired(clientIP(r)) {
+		return "", resultIdleExpired
+	}
+
+	// Grabs the username and password of the Basic Authentication. A missing header is told apart from a
+	// malformed one, as it usually means that the client has not been prompted for credentials yet.
+	header := a.authorizationHeader(r)
+	if header == "" {
+		return "", resultMissingCredentials
+	}
+
+	// Reject oversized headers before decoding them, so they cannot be used to make the server allocate memory.
+	if a.MaxCredentialLength > 0 && len(header) > a.MaxCredentialLength {
+		return "", resultInvalidScheme
+	}
+
+	// Accept a Bearer token instead of Basic credentials if requested. There is no username to work with, so the
+	// token is left entirely to the callback.
+	if token, ok := a.bearerToken(r); ok {
+		if a.BearerFallback(token) {
+			return "", resultSuccess
+		}
+
+		return "", resultInvalidCredentials
+	}
+
+	username, password, ok := a.parseCredentials(r)
+	if !ok {
+		return "", resultInvalidScheme
+	}
+
+	// Expose the extracted credentials to tests. This is a no-op unless a test has set the hook.
+	if a.debugCredentialSink != nil {
+		a.debugCredentialSink(username, password)
+	}
+
+	// Screen the credentials before calling the authenticator.
+	username, password, code, res := a.screen(username, password, true)
+	if res != resultSuccess {
+		return username, res
+	}
+
+	// Try to authenticate the user with the authenticator of the highest precedence, unless the same credentials
+	// have already been verified on the same connection.
+	cache := a.connectionCache(r, code)
+	entry := cache.entry(a, username, password)
+	if !cache.verified(a, entry) {
+		ok, err := a.authenticatorFor(r)(username, password)
+		if err != nil {
+			cache.forget(a)
+
+			// An authenticator failing because the request timed out or the server is shutting down is not broken.
+			if r.Context().Err() != nil {
+				return username, resultUnavailable
+			}
+
+			return username, resultAuthenticatorError
+		}
+
+		if !ok {
+			cache.forget(a)
+			return username, resultInvalidCredentials
+		}
+
+		cache.remember(a, entry)
+	}
+
+	// Both the password and the second factor (if any) have to be valid.
+	if code != "" {
+		if res := a.verifyTOTP(username, password, code); res != resultSuccess {
+			return username, res
+		}
+	}
+
+	a.touch(clientIP(r))
+
+	return username, resultSuccess
+}
+
+// screen runs the checks made on parsed credentials before calling the authenticator, which do not depend on the
+// request. It returns the normalized username, the password without its second factor, the second factor (if any),
+// and `resultSuccess` if the authenticator may be called. Locked out usernames are only rejected if `lockout` is set.
+func (a *BasicAuth) screen(username, password string, lockout bool) (string, string, string, result) {
+	// Reject pathological inputs targeting a specific field before doing any work with them.
+	if a.MaxUsernameLen > 0 && len(username) > a.MaxUsernameLen {
+		return "", "", "", resultUsernameTooLong
+	}
+
+	if a.MaxPasswordLen > 0 && len(password) > a.MaxPasswordLen {
+		return username, "", "", resultPasswordTooLong
+	}
+
+	// Normalize the username before anything else relies on it, see `normalizeUsername` for the order of the steps.
+	username = a.normalizeUsername(username)
+
+	// Reject denied usernames whatever the authenticator says. As with the pattern below, a comparison is still
+	// made, so these are not answered noticeably faster than other invalid credentials.
+	if a.isDeniedUser(username) {
+		CompareInputs(password, username)
+		return username, "", "", resultInvalidCredentials
+	}
+
+	// Reject usernames that cannot exist before looking them up. A comparison is still made, so these are not
+	// answered noticeably faster than other invalid credentials.
+	if a.UsernamePattern != nil && !a.UsernamePattern.MatchString(username) {
+		CompareInputs(password, username)
+		return username, "", "", resultInvalidCredentials
+	}
+
+	// Reject locked out usernames without calling the authenticator, which is what brute-force attacks target.
+	if lockout && a.isLockedOut(username) {
+		return username, "", "", resultLockedOut
+	}
+
+	// Split the second factor off the password for users with a TOTP secret.
+	password, code, ok := a.splitTOTP(username, password)
+	if !ok {
+		return username, "", "", resultInvalidCredentials
+	}
+
+	// Reject empty passwords before calling any authenticator unless explicitly allowed, so a misconfigured entry of
+	// `Users` is not an open door.
+	if password == "" && !a.AllowEmptyPassword {
+		return username, "", "", resultInvalidCredentials
+	}
+
+	// Reject known-breached passwords before authenticating. This is done regardless of whether the credentials
+	// are valid, so the response does not reveal anything about the stored credentials.
+	if a.isCompromised(password) {
+		return username, "", "", resultCompromisedPassword
+	}
+
+	// Reject revoked credentials, even if they are still valid in the store.
+	if a.isDenied(username, password) {
+		return username, "", "", resultInvalidCredentials
 	}
+
+	return username, password, code, resultSuccess
+}
+
+// acceptsContentType checks whether the media type of the body of a request is listed in `RequireContentTypes`.
+// Only the methods which usually carry a body are checked, and parameters such as `charset` are ignored.
+func (a *BasicAuth) acceptsContentType(r *http.Request) bool {
+	if len(a.RequireContentTypes) == 0 {
+		return true
+	}
+
+	if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range a.RequireContentTypes {
+		if strings.EqualFold(mediaType, allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isCompromised checks whether a password is listed in the known-breached passwords. The list is provided
+// locally by the user, so passwords (and their hashes) are never sent anywhere over the network.
+func (a *BasicAuth) isCompromised(password string) bool {
+	if len(a.CompromisedPasswords) == 0 {
+		return false
+	}
+
+	hash := sha1.Sum([]byte(password)) // Breach lists are keyed by SHA-1.
+	_, ok := a.CompromisedPasswords[strings.ToUpper(hex.EncodeToString(hash[:]))]
+
+	return ok
+}
+
+// Verify authenticates a request without sending any response. It returns `nil` on success, and an `*AuthError`
+// carrying the status code, reason, and challenge otherwise. This is useful for imperative flows, as the returned
+// error can either be inspected or sent back to the client by calling its `ServeHTTP` method.
+func (a *BasicAuth) Verify(r *http.Request) error {
+	_, res := a.authenticate(a.withRequestID(r))
+	if res == resultSuccess {
+		return nil
+	}
+
+	authErr := &AuthError{Code: res.status(), Reason: res.message()}
+	if authErr.Code == http.StatusUnauthorized {
+		authErr.Challenge = a.ChallengeFor(r)
+		if a.ProxyMode {
+			authErr.Code = http.StatusProxyAuthRequired
+		}
+	}
+
+	return authErr
 }
 
 // Authenticate is a middleware to safeguard a route with the updated version of Basic
 // Authentication (RFC 7617).
 func (a *BasicAuth) Authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return a.authenticateHandler(next, handlerName(next))
+}
+
+// Middleware is the same as `Authenticate`, but for any `http.Handler`, such as a router or a whole mux. Its
+// signature is the one expected by the middleware chains of routers, such as `r.Use(auth.Middleware)` of chi.
+func (a *BasicAuth) Middleware(next http.Handler) http.Handler {
+	return a.authenticateHandler(next, handlerName(next))
+}
+
+// authenticateHandler safeguards a handler, whose name is exposed with `HandlerNameFromContext` on success.
+func (a *BasicAuth) authenticateHandler(next http.Handler, name string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Grabs the username and password of the Basic Authentication.
-		username, password, ok := r.BasicAuth()
-		if !ok {
-			a.SendInvalidSchemeResponse(w, r)
+		if !a.authRequired(r) {
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		// Try to authenticate the user.
-		authenticated := a.Authenticator(username, password)
+		r = a.withRequestID(r)
+		username, res := a.authenticate(r)
+		if res != resultSuccess {
+			setRequestID(w, r)
+			if a.ProxyMode {
+				w = proxyResponseWriter{w}
+			}
+		}
 
-		// If not match, return 401.
-		if !authenticated {
+		switch res {
+		case resultMissingCredentials, resultChallengeFirst:
+			a.SendMissingCredentialsResponse(w, r)
+		case resultInvalidScheme:
+			a.SendInvalidSchemeResponse(w, r)
+		case resultInvalidCredentials, resultLockedOut, resultReplayedCode:
+			// If not match, return 401.
+			a.setRetryAfter(w, username)
 			a.SendInvalidCredentialsResponse(w, r)
+		case resultCompromisedPassword:
+			a.SendCompromisedPasswordResponse(w, r)
+		case resultUsernameTooLong, resultPasswordTooLong:
+			a.SendInvalidRequestResponse(w, r)
+		case resultUnsupportedMediaType:
+			a.SendUnsupportedMediaTypeResponse(w, r)
+		case resultAuthenticatorError:
+			a.SendInternalErrorResponse(w, r)
+		case resultIdleExpired:
+			a.SendIdleExpiredResponse(w, r)
+		case resultMethodNotAllowed:
+			a.SendMethodNotAllowedResponse(w, r)
+		case resultInsecureTransport:
+			a.SendInsecureTransportResponse(w, r)
+		case resultUnavailable:
+			a.SendServiceUnavailableResponse(w, r)
+		default:
+			// If match, go to the next middleware, exposing the authenticated user, the name of the protected handler,
+			// and the client IP.
+			ctx := context.WithValue(r.Context(), usernameContextKey{}, username)
+			ctx = context.WithValue(ctx, handlerNameContextKey{}, name)
+			ctx = context.WithValue(ctx, clientIPContextKey{}, clientIP(r))
+			a.serveNext(next, w, r.WithContext(ctx))
+		}
+	}
+}
+
+// AuthenticateMethods is a middleware to safeguard a route only for the listed HTTP methods (for example, `POST`,
+// `PUT`, `PATCH`, and `DELETE` for read-public / write-protected APIs). Requests with other methods are passed through
+// without authentication. Methods are case-sensitive. As `OPTIONS` is not protected unless listed, CORS preflight
+// requests (which never carry credentials) keep working. Extension methods (such as `MKCOL` of WebDAV) are unsafe
+// until proven otherwise, so they are always protected, unless listed in `SafeMethods`. With `ReadOnly`, all the
+// methods not listed in `SafeMethods` are rejected.
+func (a *BasicAuth) AuthenticateMethods(methods []string, next http.HandlerFunc) http.HandlerFunc {
+	protected := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		protected[method] = struct{}{}
+	}
+
+	authenticated := a.Authenticate(next)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := protected[r.Method]; ok || !a.isSafeMethod(r.Method) && (a.ReadOnly || isExtensionMethod(r.Method)) {
+			authenticated(w, r)
 			return
 		}
 
-		// If match, go to the next middleware.
 		next.ServeHTTP(w, r)
 	}
 }
 
+// serveNext calls the protected handler. If `RecoverNext` is set, a panic in the handler is recovered and
+// turned into `InternalErrorResponse`. Note that the status code cannot be changed anymore if the handler has
+// already written it. `http.ErrAbortHandler` is re-panicked, as it is used to abort a response on purpose.
+func (a *BasicAuth) serveNext(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	if a.RecoverNext {
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
+				atomic.AddUint64(&a.stats.errors, 1)
+				a.SendInternalErrorResponse(w, r)
+			}
+		}()
+	}
+
+	next.ServeHTTP(w, r)
+}
+
 // CompareInputs is to safe compare two inputs (prevents timing attacks).
 func CompareInputs(input, expected string) bool {
-	// Hash input and expected with fast-hash.
-	inputHash := sha256.Sum256([]byte(input))
-	expectedHash := sha256.Sum256([]byte(expected))
+	// Hash input and expected with fast-hash, so the comparison does not depend on their lengths.
+	inputHash, expectedHash := digest(input), digest(expected)
 
 	// Return boolean value with timing-safe comparisons to know whether the values
 	// passed as arguments are equal or not.
@@ -1,8 +1,20 @@
 package basic
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -86,3 +98,1383 @@ func TestAuthenticate(t *testing.T) {
 		})
 	}
 }
+
+// Tests the API key authentication, where only the password matters.
+func TestNewAPIKeyAuth(t *testing.T) {
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		expectedStatus int
+	}{
+		{
+			name:           "test_matching_secret",
+			username:       "any_username",
+			password:       "secret_key",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_matching_secret_other_username",
+			username:       "another_username",
+			password:       "secret_key",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_non_matching_secret",
+			username:       "any_username",
+			password:       "wrong_key",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewAPIKeyAuth("secret_key").Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests the imperative verification and the usage of `AuthError` both as an error and as a handler.
+func TestVerify(t *testing.T) {
+	users := map[string]string{"gerysantoso": "gerysantoso"}
+	auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Test", users)
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		expectedReason string
+	}{
+		{
+			name:           "test_success",
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			expectedReason: "",
+		},
+		{
+			name:           "test_invalid_scheme",
+			username:       "",
+			password:       "",
+			expectedReason: invalidSchemeMessage,
+		},
+		{
+			name:           "test_invalid_credentials",
+			username:       "gerysantoso",
+			password:       "wrong_password",
+			expectedReason: invalidCredentialsMessage,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.username != "" && tc.password != "" {
+				r.SetBasicAuth(tc.username, tc.password)
+			}
+
+			err := auth.Verify(r)
+			if tc.expectedReason == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got: %v.", err)
+				}
+
+				return
+			}
+
+			// Inspect the error as a structured value.
+			var authErr *AuthError
+			if !errors.As(err, &authErr) {
+				t.Fatalf("Expected an *AuthError, got: %v.", err)
+			}
+
+			if authErr.Code != http.StatusUnauthorized || authErr.Reason != tc.expectedReason {
+				t.Errorf("Unexpected error values! Code: %v. Reason: %v.", authErr.Code, authErr.Reason)
+			}
+
+			// Send the error back as a response.
+			w := httptest.NewRecorder()
+			err.(http.Handler).ServeHTTP(w, r)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusUnauthorized, w.Code)
+			}
+
+			expectedChallenge := `Basic realm="Test", charset="UTF-8"`
+			if header := w.Header().Get("WWW-Authenticate"); header != expectedChallenge {
+				t.Errorf("Expected and actual challenges are different! Expected: %v. Got: %v.", expectedChallenge, header)
+			}
+
+			if body := w.Body.String(); body != tc.expectedReason+"\n" {
+				t.Errorf("Expected and actual bodies are different! Expected: %v. Got: %v.", tc.expectedReason, body)
+			}
+		})
+	}
+}
+
+// Tests that an authenticator placed in the request context overrides the configured one.
+func TestContextWithAuthenticator(t *testing.T) {
+	users := map[string]string{"gerysantoso": "gerysantoso"}
+	tenantAuthenticator := func(username, password string) bool {
+		return username == "tenant" && password == "tenant_password"
+	}
+
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		authenticator  func(username, password string) bool
+		expectedStatus int
+	}{
+		{
+			name:           "test_configured_authenticator_without_context",
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			authenticator:  nil,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_context_authenticator_accepts",
+			username:       "tenant",
+			password:       "tenant_password",
+			authenticator:  tenantAuthenticator,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_context_authenticator_overrides_configured",
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			authenticator:  tenantAuthenticator,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewDefaultBasicAuth(users).Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+
+			if tc.authenticator != nil {
+				r = r.WithContext(ContextWithAuthenticator(r.Context(), tc.authenticator))
+			}
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests the rejection of passwords listed in a known-breached password list.
+func TestCompromisedPasswords(t *testing.T) {
+	users := map[string]string{"gerysantoso": "password123", "a_username": "a_strong_password"}
+	hash := sha1.Sum([]byte("password123")) // Breach lists are keyed by SHA-1.
+	compromised := map[string]struct{}{strings.ToUpper(hex.EncodeToString(hash[:])): {}}
+
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		response       bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "test_compromised_password_matching_stored_credentials",
+			username:       "gerysantoso",
+			password:       "password123",
+			response:       true,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   compromisedPasswordMessage,
+		},
+		{
+			name:           "test_compromised_password_not_matching_stored_credentials",
+			username:       "a_username",
+			password:       "password123",
+			response:       true,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   compromisedPasswordMessage,
+		},
+		{
+			name:           "test_compromised_password_without_response",
+			username:       "gerysantoso",
+			password:       "password123",
+			response:       false,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   invalidCredentialsMessage,
+		},
+		{
+			name:           "test_safe_password",
+			username:       "a_username",
+			password:       "a_strong_password",
+			response:       true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(users)
+			auth.CompromisedPasswords = compromised
+			if !tc.response {
+				auth.CompromisedPasswordResponse = nil
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if body := strings.TrimSpace(w.Body.String()); body != tc.expectedBody {
+				t.Errorf("Expected and actual bodies are different! Expected: %v. Got: %v.", tc.expectedBody, body)
+			}
+		})
+	}
+}
+
+// Tests the recovery from panics in the protected handler.
+func TestRecoverNext(t *testing.T) {
+	users := map[string]string{"gerysantoso": "gerysantoso"}
+	panicking := func(w http.ResponseWriter, r *http.Request) { panic("downstream failure") }
+
+	t.Run("test_recover_next_enabled", func(t *testing.T) {
+		auth := NewDefaultBasicAuth(users)
+		auth.RecoverNext = true
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		r.SetBasicAuth("gerysantoso", "gerysantoso")
+
+		auth.Authenticate(panicking)(w, r)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusInternalServerError, w.Code)
+		}
+	})
+
+	t.Run("test_recover_next_disabled", func(t *testing.T) {
+		auth := NewDefaultBasicAuth(users)
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		r.SetBasicAuth("gerysantoso", "gerysantoso")
+
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected the panic to be propagated when 'RecoverNext' is disabled.")
+			}
+		}()
+
+		auth.Authenticate(panicking)(w, r)
+	})
+
+	t.Run("test_recover_next_abort_handler", func(t *testing.T) {
+		auth := NewDefaultBasicAuth(users)
+		auth.RecoverNext = true
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		r.SetBasicAuth("gerysantoso", "gerysantoso")
+
+		defer func() {
+			if err := recover(); err != http.ErrAbortHandler {
+				t.Errorf("Expected 'http.ErrAbortHandler' to be propagated, got: %v.", err)
+			}
+		}()
+
+		auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { panic(http.ErrAbortHandler) })(w, r)
+	})
+}
+
+// Tests the removal of configured headers from failure responses.
+func TestStripHeadersOnFailure(t *testing.T) {
+	users := map[string]string{"gerysantoso": "gerysantoso"}
+	auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Test", users)
+	auth.StripHeadersOnFailure = []string{"Server", "x-powered-by"}
+
+	// Emulates a previous middleware which sets identifying headers.
+	identify := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Server", "Go")
+			w.Header().Set("X-Powered-By", "Basic")
+			next(w, r)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		password       string
+		expectedStatus int
+		expectStripped bool
+	}{
+		{
+			name:           "test_failure_strips_headers",
+			password:       "wrong_password",
+			expectedStatus: http.StatusUnauthorized,
+			expectStripped: true,
+		},
+		{
+			name:           "test_success_keeps_headers",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusOK,
+			expectStripped: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := identify(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth("gerysantoso", tc.password)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			for _, header := range []string{"Server", "X-Powered-By"} {
+				if stripped := w.Header().Get(header) == ""; stripped != tc.expectStripped {
+					t.Errorf("Unexpected presence of the '%s' header! Expected stripped: %v.", header, tc.expectStripped)
+				}
+			}
+
+			if tc.expectStripped && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected the 'WWW-Authenticate' header to be kept on failure responses.")
+			}
+		})
+	}
+}
+
+// Tests the maximum lengths of the decoded username and password.
+func TestMaxFieldLengths(t *testing.T) {
+	users := map[string]string{"gerysantoso": "gerysantoso"}
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		maxUsernameLen int
+		maxPasswordLen int
+		expectedStatus int
+		expectedResult string
+	}{
+		{
+			name:           "test_within_limits",
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			maxUsernameLen: 11,
+			maxPasswordLen: 11,
+			expectedStatus: http.StatusOK,
+			expectedResult: "success",
+		},
+		{
+			name:           "test_unlimited",
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			maxUsernameLen: 0,
+			maxPasswordLen: 0,
+			expectedStatus: http.StatusOK,
+			expectedResult: "success",
+		},
+		{
+			name:           "test_username_too_long",
+			username:       strings.Repeat("a", 65),
+			password:       "gerysantoso",
+			maxUsernameLen: 64,
+			maxPasswordLen: 64,
+			expectedStatus: http.StatusBadRequest,
+			expectedResult: "username_too_long",
+		},
+		{
+			name:           "test_password_too_long",
+			username:       "gerysantoso",
+			password:       strings.Repeat("a", 65),
+			maxUsernameLen: 64,
+			maxPasswordLen: 64,
+			expectedStatus: http.StatusBadRequest,
+			expectedResult: "password_too_long",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			auth := NewDefaultBasicAuth(users)
+			auth.AuditWriter = buf
+			auth.MaxUsernameLen = tc.maxUsernameLen
+			auth.MaxPasswordLen = tc.maxPasswordLen
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			auth.FlushAudit()
+			record := map[string]string{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("Failed to decode the audit record: %v.", err)
+			}
+
+			if record["result"] != tc.expectedResult {
+				t.Errorf("Expected and actual results are different! Expected: %v. Got: %v.", tc.expectedResult, record["result"])
+			}
+		})
+	}
+}
+
+// Tests that every request on a keep-alive connection is authenticated independently against the realm of its route.
+func TestStatelessPerRequest(t *testing.T) {
+	admin := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Admin", map[string]string{"admin": "admin_password"})
+	api := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "API", map[string]string{"gerysantoso": "gerysantoso"})
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin", admin.Authenticate(ok))
+	mux.HandleFunc("/api", api.Authenticate(ok))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	steps := []struct {
+		path           string
+		username       string
+		password       string
+		expectedStatus int
+	}{
+		{path: "/admin", username: "admin", password: "admin_password", expectedStatus: http.StatusOK},
+		{path: "/api", username: "admin", password: "admin_password", expectedStatus: http.StatusUnauthorized},
+		{path: "/api", username: "gerysantoso", password: "gerysantoso", expectedStatus: http.StatusOK},
+		{path: "/admin", username: "gerysantoso", password: "gerysantoso", expectedStatus: http.StatusUnauthorized},
+		{path: "/admin", username: "", password: "", expectedStatus: http.StatusUnauthorized},
+	}
+
+	reused := 0
+	trace := &httptrace.ClientTrace{GotConn: func(info httptrace.GotConnInfo) {
+		if info.Reused {
+			reused++
+		}
+	}}
+
+	for _, step := range steps {
+		r, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, server.URL+step.path, nil)
+		if err != nil {
+			t.Fatalf("Failed to create the request: %v.", err)
+		}
+
+		if step.username != "" {
+			r.SetBasicAuth(step.username, step.password)
+		}
+
+		res, err := server.Client().Do(r)
+		if err != nil {
+			t.Fatalf("Failed to send the request: %v.", err)
+		}
+
+		// Drain the body, so the connection can be reused by the next request.
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+
+		if res.StatusCode != step.expectedStatus {
+			t.Errorf("Expected and actual status code values are different on '%s' as '%s'! Expected: %v. Got: %v.", step.path, step.username, step.expectedStatus, res.StatusCode)
+		}
+	}
+
+	if reused != len(steps)-1 {
+		t.Errorf("Expected all requests after the first one to reuse the connection, got %v reused connections.", reused)
+	}
+}
+
+// Tests swapping the authenticator at runtime while requests are being served.
+func TestSetAuthenticator(t *testing.T) {
+	first := func(username, password string) bool { return username == "first" && password == "first" }
+	second := func(username, password string) bool { return username == "second" && password == "second" }
+
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	request := func(username, password string) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		r.SetBasicAuth(username, password)
+		handler(w, r)
+
+		return w.Code
+	}
+
+	// Swap the authenticators under load.
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				request("first", "first")
+				request("second", "second")
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				auth.SetAuthenticator(first)
+			} else {
+				auth.SetAuthenticator(second)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	tests := []struct {
+		name          string
+		authenticator func(username, password string) bool
+		expectedCodes map[string]int
+	}{
+		{
+			name:          "test_first_authenticator",
+			authenticator: first,
+			expectedCodes: map[string]int{"first": http.StatusOK, "second": http.StatusUnauthorized, "gerysantoso": http.StatusUnauthorized},
+		},
+		{
+			name:          "test_second_authenticator",
+			authenticator: second,
+			expectedCodes: map[string]int{"first": http.StatusUnauthorized, "second": http.StatusOK, "gerysantoso": http.StatusUnauthorized},
+		},
+		{
+			name:          "test_reset_to_configured_authenticator",
+			authenticator: nil,
+			expectedCodes: map[string]int{"first": http.StatusUnauthorized, "second": http.StatusUnauthorized, "gerysantoso": http.StatusOK},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth.SetAuthenticator(tc.authenticator)
+
+			for username, expectedStatus := range tc.expectedCodes {
+				if code := request(username, username); code != expectedStatus {
+					t.Errorf("Expected and actual status code values are different for '%s'! Expected: %v. Got: %v.", username, expectedStatus, code)
+				}
+			}
+		})
+	}
+}
+
+// Tests the distinction between missing credentials and a malformed or non-Basic scheme.
+func TestMissingCredentialsResponse(t *testing.T) {
+	missing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+	tests := []struct {
+		name           string
+		header         string
+		response       http.Handler
+		expectedStatus int
+	}{
+		{
+			name:           "test_missing_header",
+			header:         "",
+			response:       missing,
+			expectedStatus: http.StatusTeapot,
+		},
+		{
+			name:           "test_missing_header_default_response",
+			header:         "",
+			response:       nil,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_malformed_header",
+			header:         "Basic not_base64!",
+			response:       missing,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_non_basic_scheme",
+			header:         "Bearer token",
+			response:       missing,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Test", nil)
+			auth.MissingCredentialsResponse = tc.response
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			if tc.header != "" {
+				r.Header.Set("Authorization", tc.header)
+			}
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected the challenge to be sent on every failure.")
+			}
+		})
+	}
+}
+
+// Tests the status codes of the default failure responses, which do not affect custom responses.
+func TestFailureStatuses(t *testing.T) {
+	teapot := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+	tests := []struct {
+		name              string
+		configure         func(auth *BasicAuth)
+		send              func(auth *BasicAuth, w http.ResponseWriter, r *http.Request)
+		expectedStatus    int
+		expectedChallenge bool
+	}{
+		{
+			name:              "test_default_invalid_credentials_status",
+			configure:         func(auth *BasicAuth) {},
+			send:              (*BasicAuth).SendInvalidCredentialsResponse,
+			expectedStatus:    http.StatusUnauthorized,
+			expectedChallenge: true,
+		},
+		{
+			name:              "test_custom_invalid_credentials_status",
+			configure:         func(auth *BasicAuth) { auth.InvalidCredentialsStatus = http.StatusForbidden },
+			send:              (*BasicAuth).SendInvalidCredentialsResponse,
+			expectedStatus:    http.StatusForbidden,
+			expectedChallenge: true,
+		},
+		{
+			name:              "test_default_invalid_scheme_status",
+			configure:         func(auth *BasicAuth) { auth.InvalidCredentialsStatus = http.StatusForbidden },
+			send:              (*BasicAuth).SendInvalidSchemeResponse,
+			expectedStatus:    http.StatusUnauthorized,
+			expectedChallenge: true,
+		},
+		{
+			name:              "test_custom_invalid_scheme_status",
+			configure:         func(auth *BasicAuth) { auth.InvalidSchemeStatus = http.StatusForbidden },
+			send:              (*BasicAuth).SendInvalidSchemeResponse,
+			expectedStatus:    http.StatusForbidden,
+			expectedChallenge: true,
+		},
+		{
+			name: "test_custom_response_not_affected",
+			configure: func(auth *BasicAuth) {
+				auth.InvalidCredentialsStatus = http.StatusForbidden
+				auth.InvalidCredentialsResponse = teapot
+			},
+			send:              (*BasicAuth).SendInvalidCredentialsResponse,
+			expectedStatus:    http.StatusTeapot,
+			expectedChallenge: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Test", nil)
+			tc.configure(auth)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+
+			tc.send(auth, w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if challenge := w.Header().Get("WWW-Authenticate") != ""; challenge != tc.expectedChallenge {
+				t.Errorf("Expected and actual challenges are different! Expected: %v. Got: %v.", tc.expectedChallenge, challenge)
+			}
+		})
+	}
+}
+
+// Tests that `Middleware` composes with router-style middleware chains, and behaves as `Authenticate`.
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name             string
+		username         string
+		password         string
+		path             string
+		expectedStatus   int
+		expectedUsername string
+	}{
+		{
+			name:             "test_valid_credentials",
+			username:         "gerysantoso",
+			password:         "gerysantoso",
+			path:             "/private",
+			expectedStatus:   http.StatusOK,
+			expectedUsername: "gerysantoso",
+		},
+		{
+			name:           "test_invalid_credentials",
+			username:       "gerysantoso",
+			password:       "wrong_password",
+			path:           "/private",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_missing_credentials",
+			path:           "/private",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:             "test_unknown_route_behind_the_middleware",
+			username:         "gerysantoso",
+			password:         "gerysantoso",
+			path:             "/unknown",
+			expectedStatus:   http.StatusNotFound,
+			expectedUsername: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+
+			var username, name string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+				username, _ = UsernameFromContext(r.Context())
+				name, _ = HandlerNameFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			// Compose the middlewares the way routers do with `Use`.
+			var handler http.Handler = mux
+			for _, middleware := range []func(http.Handler) http.Handler{auth.Middleware} {
+				handler = middleware(handler)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			w := httptest.NewRecorder()
+			if tc.username != "" {
+				r.SetBasicAuth(tc.username, tc.password)
+			}
+
+			handler.ServeHTTP(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if username != tc.expectedUsername {
+				t.Errorf("Expected and actual usernames are different! Expected: %v. Got: %v.", tc.expectedUsername, username)
+			}
+
+			if tc.expectedUsername != "" && name != "*http.ServeMux" {
+				t.Errorf("Expected and actual handler names are different! Expected: %v. Got: %v.", "*http.ServeMux", name)
+			}
+		})
+	}
+}
+
+// Tests that empty passwords are rejected by default, even if they match the value of the user in `Users` or a custom
+// authenticator would accept them, and that they are accepted if `AllowEmptyPassword` is set.
+func TestAllowEmptyPassword(t *testing.T) {
+	tests := []struct {
+		name           string
+		allow          bool
+		custom         bool
+		username       string
+		password       string
+		expectedStatus int
+	}{
+		{
+			name:           "test_empty_password_rejected_by_default",
+			allow:          false,
+			username:       "legacy",
+			password:       "",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_empty_password_allowed",
+			allow:          true,
+			username:       "legacy",
+			password:       "",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_empty_password_of_other_user",
+			allow:          true,
+			username:       "gerysantoso",
+			password:       "",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_non_empty_password_by_default",
+			allow:          false,
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_empty_password_rejected_before_custom_authenticator",
+			allow:          false,
+			custom:         true,
+			username:       "legacy",
+			password:       "",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_empty_password_allowed_with_custom_authenticator",
+			allow:          true,
+			custom:         true,
+			username:       "legacy",
+			password:       "",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso", "legacy": ""})
+			auth.AllowEmptyPassword = tc.allow
+			if tc.custom {
+				auth.Authenticator = func(username, password string) bool { return username == "legacy" }
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests the authentication of specific HTTP methods only.
+func TestAuthenticateMethods(t *testing.T) {
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	handler := auth.AuthenticateMethods([]string{http.MethodPost, http.MethodDelete}, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		credentials    bool
+		expectedStatus int
+	}{
+		{name: "test_public_get", method: http.MethodGet, credentials: false, expectedStatus: http.StatusOK},
+		{name: "test_public_options_preflight", method: http.MethodOptions, credentials: false, expectedStatus: http.StatusOK},
+		{name: "test_protected_post_without_credentials", method: http.MethodPost, credentials: false, expectedStatus: http.StatusUnauthorized},
+		{name: "test_protected_post_with_credentials", method: http.MethodPost, credentials: true, expectedStatus: http.StatusOK},
+		{name: "test_protected_delete_without_credentials", method: http.MethodDelete, credentials: false, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/", nil)
+			w := httptest.NewRecorder()
+			if tc.credentials {
+				r.SetBasicAuth("gerysantoso", "gerysantoso")
+			}
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests that failure responses to HEAD requests have no body, but keep the status code and the challenge.
+func TestHeadFailureResponses(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		expectedBody   bool
+		expectedStatus int
+	}{
+		{name: "test_head_bodyless", method: http.MethodHead, expectedBody: false, expectedStatus: http.StatusUnauthorized},
+		{name: "test_get_with_body", method: http.MethodGet, expectedBody: true, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Test", map[string]string{"gerysantoso": "gerysantoso"})
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(tc.method, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth("gerysantoso", "wrong_password")
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if hasBody := w.Body.Len() > 0; hasBody != tc.expectedBody {
+				t.Errorf("Unexpected response body! Expected body: %v. Got: %q.", tc.expectedBody, w.Body.String())
+			}
+
+			if w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected the challenge to be sent.")
+			}
+		})
+	}
+}
+
+// Tests that usernames not matching `UsernamePattern` are rejected before reaching the authenticator.
+func TestUsernamePattern(t *testing.T) {
+	tests := []struct {
+		name           string
+		username       string
+		expectedStatus int
+		expectedCalls  int
+	}{
+		{
+			name:           "test_matching_username",
+			username:       "gery@example.com",
+			expectedStatus: http.StatusOK,
+			expectedCalls:  1,
+		},
+		{
+			name:           "test_non_matching_username",
+			username:       "gerysantoso",
+			expectedStatus: http.StatusUnauthorized,
+			expectedCalls:  0,
+		},
+		{
+			name:           "test_partially_matching_username",
+			username:       "gery@example.com\nadmin",
+			expectedStatus: http.StatusUnauthorized,
+			expectedCalls:  0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			auth := NewDefaultBasicAuth(nil)
+			auth.UsernamePattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+$`)
+			auth.Authenticator = func(username, password string) bool {
+				calls++
+				return password == "gerysantoso"
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, "gerysantoso")
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if calls != tc.expectedCalls {
+				t.Errorf("Expected and actual authenticator calls are different! Expected: %v. Got: %v.", tc.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+// Tests that bodies with media types not listed in `RequireContentTypes` are rejected before authenticating.
+func TestRequireContentTypes(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		contentType    string
+		expectedStatus int
+		expectedCalls  int
+	}{
+		{
+			name:           "test_allowed_content_type",
+			method:         http.MethodPost,
+			contentType:    "application/json",
+			expectedStatus: http.StatusOK,
+			expectedCalls:  1,
+		},
+		{
+			name:           "test_allowed_content_type_with_parameters",
+			method:         http.MethodPut,
+			contentType:    "Application/JSON; charset=utf-8",
+			expectedStatus: http.StatusOK,
+			expectedCalls:  1,
+		},
+		{
+			name:           "test_disallowed_content_type",
+			method:         http.MethodPost,
+			contentType:    "text/xml",
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedCalls:  0,
+		},
+		{
+			name:           "test_missing_content_type",
+			method:         http.MethodPatch,
+			contentType:    "",
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedCalls:  0,
+		},
+		{
+			name:           "test_method_without_body",
+			method:         http.MethodGet,
+			contentType:    "",
+			expectedStatus: http.StatusOK,
+			expectedCalls:  1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			auth := NewDefaultBasicAuth(nil)
+			auth.RequireContentTypes = []string{"application/json", "application/x-www-form-urlencoded"}
+			auth.Authenticator = func(username, password string) bool {
+				calls++
+				return true
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(tc.method, "/", strings.NewReader("{}"))
+			w := httptest.NewRecorder()
+			if tc.contentType != "" {
+				r.Header.Set("Content-Type", tc.contentType)
+			}
+			r.SetBasicAuth("gerysantoso", "gerysantoso")
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if calls != tc.expectedCalls {
+				t.Errorf("Expected and actual authenticator calls are different! Expected: %v. Got: %v.", tc.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+// Tests that `AuthenticatorWithRequest` receives the request, and takes precedence over `Authenticator`.
+func TestAuthenticatorWithRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		withRequest    bool
+		withContext    bool
+		expectedStatus int
+	}{
+		{
+			name:           "test_scoped_route_allowed",
+			path:           "/reports",
+			withRequest:    true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_scoped_route_denied",
+			path:           "/admin",
+			withRequest:    true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_falls_back_to_authenticator",
+			path:           "/admin",
+			withRequest:    false,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_context_authenticator_takes_precedence",
+			path:           "/admin",
+			withRequest:    true,
+			withContext:    true,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			if tc.withRequest {
+				auth.AuthenticatorWithRequest = func(r *http.Request, username, password string) bool {
+					return r.URL.Path == "/reports" && auth.verifyUser(username, password)
+				}
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			w := httptest.NewRecorder()
+			if tc.withContext {
+				r = r.WithContext(ContextWithAuthenticator(r.Context(), func(username, password string) bool { return true }))
+			}
+			r.SetBasicAuth("gerysantoso", "gerysantoso")
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests the precedence between all the ways to set an authenticator. Only one of them is called per request.
+func TestAuthenticatorPrecedence(t *testing.T) {
+	tests := []struct {
+		name               string
+		withContext        bool
+		withRequest        bool
+		withCtx            bool
+		withE              bool
+		withSwapped        bool
+		withAuthenticator  bool
+		expectedCalled     string
+		expectedStatusCode int
+	}{
+		{
+			name:               "test_all_set",
+			withContext:        true,
+			withRequest:        true,
+			withSwapped:        true,
+			withAuthenticator:  true,
+			expectedCalled:     "context",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "test_request_over_context_aware",
+			withRequest:        true,
+			withCtx:            true,
+			withE:              true,
+			withAuthenticator:  true,
+			expectedCalled:     "request",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "test_context_aware_over_error_returning",
+			withCtx:            true,
+			withE:              true,
+			withAuthenticator:  true,
+			expectedCalled:     "context_aware",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "test_error_returning_over_authenticator",
+			withE:              true,
+			withAuthenticator:  true,
+			expectedCalled:     "error_returning",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "test_swapped_over_configured",
+			withRequest:        true,
+			withCtx:            true,
+			withE:              true,
+			withSwapped:        true,
+			withAuthenticator:  true,
+			expectedCalled:     "swapped",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "test_swapped_over_authenticator",
+			withSwapped:        true,
+			withAuthenticator:  true,
+			expectedCalled:     "swapped",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "test_context_over_authenticator",
+			withContext:        true,
+			withAuthenticator:  true,
+			expectedCalled:     "context",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "test_authenticator_only",
+			withAuthenticator:  true,
+			expectedCalled:     "authenticator",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "test_static_users_default",
+			expectedCalled:     "",
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			called := []string{}
+			record := func(name string) func(username, password string) bool {
+				return func(username, password string) bool {
+					called = append(called, name)
+					return true
+				}
+			}
+
+			// A struct literal is used, so the default authenticator is only reached through the fallback.
+			auth := &BasicAuth{Users: map[string]string{"gerysantoso": "gerysantoso"}}
+			if tc.withRequest {
+				auth.AuthenticatorWithRequest = func(r *http.Request, username, password string) bool {
+					return record("request")(username, password)
+				}
+			}
+			if tc.withCtx {
+				auth.AuthenticatorCtx = func(ctx context.Context, username, password string) (bool, error) {
+					return record("context_aware")(username, password), nil
+				}
+			}
+			if tc.withE {
+				auth.AuthenticatorE = func(username, password string) (bool, error) {
+					return record("error_returning")(username, password), nil
+				}
+			}
+			if tc.withSwapped {
+				auth.SetAuthenticator(record("swapped"))
+			}
+			if tc.withAuthenticator {
+				auth.Authenticator = record("authenticator")
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			if tc.withContext {
+				r = r.WithContext(ContextWithAuthenticator(r.Context(), record("context")))
+			}
+			r.SetBasicAuth("gerysantoso", "gerysantoso")
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.expectedStatusCode {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatusCode, w.Code)
+			}
+
+			expected := []string{}
+			if tc.expectedCalled != "" {
+				expected = append(expected, tc.expectedCalled)
+			}
+
+			if !reflect.DeepEqual(called, expected) {
+				t.Errorf("Expected and actual called authenticators are different! Expected: %v. Got: %v.", expected, called)
+			}
+		})
+	}
+}
+
+// Tests that errors of `AuthenticatorE` are answered with `InternalErrorResponse` instead of a challenge.
+func TestAuthenticatorE(t *testing.T) {
+	errUnreachable := errors.New("database unreachable")
+	tests := []struct {
+		name               string
+		valid              bool
+		err                error
+		expectedStatusCode int
+		expectedChallenge  bool
+		expectedErrors     uint64
+	}{
+		{
+			name:               "test_valid_credentials",
+			valid:              true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "test_invalid_credentials",
+			valid:              false,
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedChallenge:  true,
+		},
+		{
+			name:               "test_authenticator_error",
+			err:                errUnreachable,
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedErrors:     1,
+		},
+		{
+			name:               "test_authenticator_error_with_valid_result",
+			valid:              true,
+			err:                errUnreachable,
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedErrors:     1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Private", nil)
+			auth.AuthenticatorE = func(username, password string) (bool, error) {
+				return tc.valid, tc.err
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth("gerysantoso", "gerysantoso")
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.expectedStatusCode {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatusCode, w.Code)
+			}
+
+			if challenged := w.Header().Get("WWW-Authenticate") != ""; challenged != tc.expectedChallenge {
+				t.Errorf("Expected and actual challenges are different! Expected: %v. Got: %v.", tc.expectedChallenge, challenged)
+			}
+
+			if errs := auth.Stats().Errors; errs != tc.expectedErrors {
+				t.Errorf("Expected and actual error counts are different! Expected: %v. Got: %v.", tc.expectedErrors, errs)
+			}
+		})
+	}
+}
+
+// Tests that the authenticator set by the constructors can be wrapped, and still verifies `Users`.
+func TestWrapDefaultAuthenticator(t *testing.T) {
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	authenticator := auth.Authenticator
+	auth.Authenticator = func(username, password string) bool {
+		return username != "nicholasdwiarto" && authenticator(username, password)
+	}
+
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		expectedStatus int
+	}{
+		{name: "test_valid_user", username: "gerysantoso", password: "gerysantoso", expectedStatus: http.StatusOK},
+		{name: "test_invalid_password", username: "gerysantoso", password: "wrong", expectedStatus: http.StatusUnauthorized},
+		{name: "test_rejected_by_wrapper", username: "nicholasdwiarto", password: "nicholasdwiarto", expectedStatus: http.StatusUnauthorized},
+	}
+
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+			handler(w, r)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Package basicbcrypt provides bcrypt-hashed credentials for `github.com/lauslim12/basic`. It lives in its own
+// module, so `golang.org/x/crypto` is only required by programs which import this package, and not by every
+// importer of the core module.
+//
+// The values of `Users` are expected to be bcrypt hashes, such as the ones generated with
+// `bcrypt.GenerateFromPassword` or `htpasswd -B`, so no plaintext passwords have to be checked into configuration.
+package basicbcrypt
+
+import (
+	"crypto/rand"
+	"errors"
+	"sync"
+
+	"github.com/lauslim12/basic"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Verifier is a `basic.PasswordVerifier`, where the stored values are bcrypt hashes. Passwords are compared with
+// the constant-time comparison of bcrypt. A malformed hash is reported as an error, and never panics.
+type Verifier struct{}
+
+// Verify compares the supplied password with the stored bcrypt hash.
+func (Verifier) Verify(password, stored string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(stored), []byte(password))
+
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// HashPassword checks a password against a `basic.PasswordPolicy`, and hashes it with bcrypt and the default cost, for
+// the users of `NewBasicAuth`. An error wrapping `basic.ErrWeakPassword` is returned if the password does not satisfy
+// the policy, as hashes cannot be checked once they are stored.
+func HashPassword(password string, policy basic.PasswordPolicy) (string, error) {
+	if err := policy.Check(password); err != nil {
+		return "", err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// AddUser hashes a password with `HashPassword` under the `PasswordPolicy` of an instance, and adds the user with
+// `AddUser` of the instance. It is safe to call concurrently with requests.
+func AddUser(auth *basic.BasicAuth, username, password string) error {
+	hash, err := HashPassword(password, auth.PasswordPolicy)
+	if err != nil {
+		return err
+	}
+
+	return auth.AddUser(username, hash)
+}
+
+// NewBasicAuth is used to set up Basic Auth options with default configurations, where the values of `users` are
+// bcrypt hashes instead of plaintext passwords. Users can be added and removed while serving with `AddUser` and
+// `RemoveUser`. Unknown usernames are verified against a dummy hash, so the time taken does not tell which usernames
+// exist. Malformed hashes never match.
+func NewBasicAuth(users map[string]string) *basic.BasicAuth {
+	auth := basic.NewDefaultBasicAuth(users)
+	auth.PasswordVerifier = Verifier{}
+	auth.Authenticator = func(username, password string) bool {
+		return verify(auth.LookupUser, username, password)
+	}
+
+	return auth
+}
+
+// dummyHash is a bcrypt hash of a random password, generated once on first use with the default cost.
+var (
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
+
+// verify checks a pair of credentials against bcrypt-hashed users. An unknown username is still verified, against
+// `dummyHash`, so it takes as long as a known one. Malformed hashes never match.
+func verify(lookup func(username string) (string, bool), username, password string) bool {
+	hash, found := lookup(username)
+	if !found {
+		dummyHashOnce.Do(func() {
+			secret := make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				panic("basicbcrypt: failed to generate a random password: " + err.Error())
+			}
+
+			dummyHash, _ = bcrypt.GenerateFromPassword(secret, bcrypt.DefaultCost)
+		})
+		hash = string(dummyHash)
+	}
+
+	valid, err := Verifier{}.Verify(password, hash)
+
+	return found && err == nil && valid
+}
@@ -0,0 +1,136 @@
+package basicbcrypt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lauslim12/basic"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Tests the default configuration with bcrypt-hashed users.
+func TestNewBasicAuth(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("gerysantoso"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	empty, err := bcrypt.GenerateFromPassword([]byte(""), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := map[string]string{
+		"gerysantoso":     string(hash),
+		"legacy":          string(empty),
+		"nicholasdwiarto": "not-a-bcrypt-hash",
+		"plaintext":       "plaintext",
+	}
+
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		expectedStatus int
+	}{
+		{
+			name:           "test_valid_credentials",
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_invalid_password",
+			username:       "gerysantoso",
+			password:       "wrong",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_unknown_user",
+			username:       "unknown",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_malformed_hash",
+			username:       "nicholasdwiarto",
+			password:       "not-a-bcrypt-hash",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_plaintext_is_not_accepted",
+			username:       "plaintext",
+			password:       "plaintext",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_empty_password_rejected_by_default",
+			username:       "legacy",
+			password:       "",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	auth := NewBasicAuth(users)
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests that the verifier reports malformed hashes as errors instead of panicking.
+func TestVerifier(t *testing.T) {
+	if valid, err := (Verifier{}).Verify("password", "malformed"); valid || err == nil {
+		t.Errorf("Expected a malformed hash to be rejected with an error. Got: %v, %v.", valid, err)
+	}
+}
+
+// Tests that users are only added with passwords satisfying the policy, and are stored as bcrypt hashes.
+func TestAddUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		password       string
+		expectedErr    bool
+		expectedStatus int
+	}{
+		{name: "test_strong_password", password: "Correct-Horse-9", expectedErr: false, expectedStatus: http.StatusOK},
+		{name: "test_weak_password", password: "password", expectedErr: true, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewBasicAuth(nil)
+			auth.PasswordPolicy = basic.PasswordPolicy{MinPasswordLen: 12, RequireMixedCase: true, RequireDigits: true, RequireSymbols: true}
+
+			err := AddUser(auth, "gerysantoso", tc.password)
+			if (err != nil) != tc.expectedErr || err != nil && !errors.Is(err, basic.ErrWeakPassword) {
+				t.Fatalf("Expected and actual errors are different! Expected an error: %v. Got: %v.", tc.expectedErr, err)
+			}
+
+			if stored, ok := auth.LookupUser("gerysantoso"); ok && stored == tc.password {
+				t.Errorf("Expected the password to be stored as a bcrypt hash. Got: %v.", stored)
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth("gerysantoso", tc.password)
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package basicbcrypt
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lauslim12/basic"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Parameters of Argon2id, as recommended by the documentation of `golang.org/x/crypto/argon2`.
+const (
+	argon2Time    = 1
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+	argon2KeyLen  = 32
+)
+
+// argon2Verifier is a benchmark verifier, where users are stored as `salt$key`, both encoded in Base64. It is only
+// used as a point of comparison for `Verifier`.
+type argon2Verifier struct{}
+
+// Verify derives the key of the supplied password and compares it with the stored key in constant time.
+func (argon2Verifier) Verify(password, stored string) (bool, error) {
+	encodedSalt, encodedKey, _ := strings.Cut(stored, "$")
+	salt, err := base64.RawStdEncoding.DecodeString(encodedSalt)
+	if err != nil {
+		return false, err
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return false, err
+	}
+
+	derived := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+
+	return subtle.ConstantTimeCompare(derived, key) == 1, nil
+}
+
+// hashArgon2 hashes a password with Argon2id and a random salt, in the format expected by `argon2Verifier`.
+func hashArgon2(b *testing.B, password string) string {
+	b.Helper()
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		b.Fatalf("Failed to generate a salt: %v.", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+
+	return base64.RawStdEncoding.EncodeToString(salt) + "$" + base64.RawStdEncoding.EncodeToString(key)
+}
+
+// benchmarkAuthenticate measures the throughput of `Authenticate` under concurrent load, and reports the
+// 99th percentile latency of a single request as a custom metric.
+func benchmarkAuthenticate(b *testing.B, stored string, verifier basic.PasswordVerifier) {
+	auth := basic.NewDefaultBasicAuth(map[string]string{"gerysantoso": stored})
+	auth.PasswordVerifier = verifier
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	var mu sync.Mutex
+	latencies := make([]time.Duration, 0, b.N)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		local := make([]time.Duration, 0, 64)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.SetBasicAuth("gerysantoso", "gerysantoso")
+
+		for pb.Next() {
+			w := httptest.NewRecorder()
+			start := time.Now()
+			handler(w, r)
+			local = append(local, time.Since(start))
+
+			if w.Code != http.StatusOK {
+				b.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusOK, w.Code)
+			}
+		}
+
+		mu.Lock()
+		latencies = append(latencies, local...)
+		mu.Unlock()
+	})
+	b.StopTimer()
+
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		b.ReportMetric(float64(latencies[len(latencies)*99/100].Nanoseconds()), "p99-ns/op")
+	}
+}
+
+// Benchmarks `Verifier` with the default cost.
+func BenchmarkAuth_Bcrypt(b *testing.B) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("gerysantoso"), bcrypt.DefaultCost)
+	if err != nil {
+		b.Fatalf("Failed to hash the password: %v.", err)
+	}
+
+	benchmarkAuthenticate(b, string(hash), Verifier{})
+}
+
+// Benchmarks an Argon2id verifier with the recommended parameters.
+func BenchmarkAuth_Argon2(b *testing.B) {
+	benchmarkAuthenticate(b, hashArgon2(b, "gerysantoso"), argon2Verifier{})
+}
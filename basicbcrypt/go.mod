@@ -0,0 +1,10 @@
+module github.com/lauslim12/basic/basicbcrypt
+
+go 1.18
+
+require (
+	github.com/lauslim12/basic v1.1.0
+	golang.org/x/crypto v0.23.0
+)
+
+require golang.org/x/sys v0.20.0 // indirect
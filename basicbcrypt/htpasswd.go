@@ -0,0 +1,125 @@
+package basicbcrypt
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lauslim12/basic"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ParseHtpasswd reads users from an htpasswd file, with one `username:hash` pair per line. Only bcrypt hashes (as
+// generated with `htpasswd -B`) are accepted, as the other formats of htpasswd are obsolete. Empty lines and lines
+// starting with `#` are skipped. Any invalid line fails the whole file, so a half-written file is never loaded.
+func ParseHtpasswd(r io.Reader) (map[string]string, error) {
+	users := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		username, hash, ok := strings.Cut(text, ":")
+		if !ok || username == "" {
+			return nil, fmt.Errorf("basicbcrypt: line %d is not a 'username:hash' pair", line)
+		}
+
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("basicbcrypt: line %d does not have a bcrypt hash: %w", line, err)
+		}
+
+		users[username] = hash
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("basicbcrypt: failed to read the htpasswd file: %w", err)
+	}
+
+	return users, nil
+}
+
+// WatchHtpasswd loads the users of `auth` from an htpasswd file, and reloads them whenever the modification time or the
+// size of the file changes, checked every `interval`, until the context is done. Users can then be added and removed
+// without restarting. Each reload swaps the whole set of users at once with `SetAuthenticator`, which outranks the
+// authenticators configured on `auth`, so requests are always verified against a consistent snapshot. If a reload
+// fails, the previous set is kept until the file changes again, and the error is passed to `onError` if it is not
+// `nil`. An error is only returned if the first load fails.
+func WatchHtpasswd(ctx context.Context, auth *basic.BasicAuth, path string, interval time.Duration, onError func(error)) error {
+	info, err := load(auth, path)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := os.Stat(path)
+			if err != nil {
+				report(onError, err)
+				continue
+			}
+
+			if current.ModTime().Equal(info.ModTime()) && current.Size() == info.Size() {
+				continue
+			}
+
+			// A broken file is only reported once, and retried when it changes again.
+			info = current
+			if _, err := load(auth, path); err != nil {
+				report(onError, err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// load parses an htpasswd file, and swaps the authenticator of `auth` for one verifying its users. It returns the
+// information of the file that was loaded.
+func load(auth *basic.BasicAuth, path string) (os.FileInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := ParseHtpasswd(file)
+	if err != nil {
+		return nil, err
+	}
+
+	auth.SetAuthenticator(func(username, password string) bool {
+		return verify(func(username string) (string, bool) {
+			hash, ok := users[username]
+			return hash, ok
+		}, username, password)
+	})
+
+	return info, nil
+}
+
+// report passes a reload error to the error callback, if any.
+func report(onError func(error), err error) {
+	if onError != nil {
+		onError(err)
+	}
+}
@@ -0,0 +1,159 @@
+package basicbcrypt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// hash generates a bcrypt hash with the lowest cost, to keep the tests fast.
+func hash(t *testing.T, password string) string {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(hashed)
+}
+
+// Tests parsing htpasswd files.
+func TestParseHtpasswd(t *testing.T) {
+	hashed := hash(t, "gerysantoso")
+	tests := []struct {
+		name          string
+		content       string
+		expectedUsers int
+		expectedError bool
+	}{
+		{
+			name:          "test_valid_file",
+			content:       "# Users\ngerysantoso:" + hashed + "\n\nnicholasdwiarto:" + hashed + "\n",
+			expectedUsers: 2,
+		},
+		{
+			name:          "test_empty_file",
+			content:       "",
+			expectedUsers: 0,
+		},
+		{
+			name:          "test_missing_separator",
+			content:       "gerysantoso\n",
+			expectedError: true,
+		},
+		{
+			name:          "test_non_bcrypt_hash",
+			content:       "gerysantoso:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			users, err := ParseHtpasswd(strings.NewReader(tc.content))
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("Expected and actual errors are different! Expected an error: %v. Got: %v.", tc.expectedError, err)
+			}
+
+			if len(users) != tc.expectedUsers {
+				t.Errorf("Expected and actual numbers of users are different! Expected: %v. Got: %v.", tc.expectedUsers, len(users))
+			}
+		})
+	}
+}
+
+// Tests that the users are reloaded when the htpasswd file changes, and kept when the new file is invalid.
+func TestWatchHtpasswd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".htpasswd")
+	write := func(content string, age time.Duration) {
+		// Replace the file atomically, so that the watcher never sees it half-written.
+		temp := path + ".tmp"
+		if err := os.WriteFile(temp, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		// Set the modification time explicitly, as the resolution of some file systems is too coarse.
+		modTime := time.Now().Add(age)
+		if err := os.Chtimes(temp, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.Rename(temp, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	auth := NewBasicAuth(nil)
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	status := func(username, password string) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		r.SetBasicAuth(username, password)
+		handler(w, r)
+
+		return w.Code
+	}
+	eventually := func(condition func() bool) bool {
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			if condition() {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	write("gerysantoso:"+hash(t, "gerysantoso")+"\n", -time.Hour)
+
+	errs := make(chan error, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := WatchHtpasswd(ctx, auth, path, 5*time.Millisecond, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := status("gerysantoso", "gerysantoso"); code != http.StatusOK {
+		t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusOK, code)
+	}
+
+	// Replace the users.
+	write("nicholasdwiarto:"+hash(t, "nicholasdwiarto")+"\n", -time.Minute)
+	if !eventually(func() bool { return status("nicholasdwiarto", "nicholasdwiarto") == http.StatusOK }) {
+		t.Fatal("Expected the new user to be loaded.")
+	}
+
+	if code := status("gerysantoso", "gerysantoso"); code != http.StatusUnauthorized {
+		t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusUnauthorized, code)
+	}
+
+	// Break the file, which keeps the previous users.
+	write("not a valid line\n", 0)
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the reload error to be reported.")
+	}
+
+	if code := status("nicholasdwiarto", "nicholasdwiarto"); code != http.StatusOK {
+		t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusOK, code)
+	}
+}
+
+// Tests that the first load fails if the file cannot be loaded.
+func TestWatchHtpasswdMissingFile(t *testing.T) {
+	err := WatchHtpasswd(context.Background(), NewBasicAuth(nil), filepath.Join(t.TempDir(), "missing"), time.Second, nil)
+	if err == nil {
+		t.Error("Expected an error for a missing file.")
+	}
+}
@@ -0,0 +1,58 @@
+// Package basicgin adapts `github.com/lauslim12/basic` to the Gin web framework. It is a module of its own, so the
+// core package does not depend on Gin.
+//
+// The adapter runs `Authenticate` with all the configuration of the `basic.BasicAuth` instance, so the failure
+// responses, the lockout, the audit trail, and every other option behave exactly as with `net/http`.
+package basicgin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lauslim12/basic"
+)
+
+// UsernameKey is the key of the authenticated username in the Gin context, for `c.GetString(basicgin.UsernameKey)`.
+// It is also available with `basic.UsernameFromContext(c.Request.Context())`.
+const UsernameKey = "basic.username"
+
+// Middleware returns a Gin middleware authenticating requests with `a`. On success, the username is stored in the
+// Gin context under `UsernameKey`, and the next handlers are called with `c.Next()`. Otherwise, the configured failure
+// response is sent, and the chain is stopped with `c.Abort()`. The handler of `a` is built once here, not per request.
+func Middleware(a *basic.BasicAuth) gin.HandlerFunc {
+	handler := a.Authenticate(next)
+
+	return func(c *gin.Context) {
+		state := &ginState{context: c}
+		handler(c.Writer, c.Request.WithContext(context.WithValue(c.Request.Context(), ginStateKey{}, state)))
+
+		if !state.authenticated {
+			c.Abort()
+		}
+	}
+}
+
+// ginStateKey is the key of the `ginState` of a request in the context of the request handed to `Authenticate`.
+type ginStateKey struct{}
+
+// ginState carries the Gin context of a request through `Authenticate`, and whether it has been authenticated.
+type ginState struct {
+	context       *gin.Context
+	authenticated bool
+}
+
+// next is the handler protected by `Authenticate`, which resumes the Gin chain of the request.
+func next(w http.ResponseWriter, r *http.Request) {
+	state := r.Context().Value(ginStateKey{}).(*ginState)
+	state.authenticated = true
+
+	// Keep the request carrying the username, the handler name, and the client IP for the next handlers.
+	c := state.context
+	c.Request = r
+	if username, ok := basic.UsernameFromContext(r.Context()); ok {
+		c.Set(UsernameKey, username)
+	}
+
+	c.Next()
+}
@@ -0,0 +1,87 @@
+package basicgin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lauslim12/basic"
+)
+
+// Tests the authentication of Gin routes, with the configured failure responses and the username in the context.
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name              string
+		username          string
+		password          string
+		expectedStatus    int
+		expectedBody      string
+		expectedChallenge string
+	}{
+		{
+			name:           "test_valid_credentials",
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "gerysantoso gerysantoso",
+		},
+		{
+			name:              "test_invalid_credentials",
+			username:          "gerysantoso",
+			password:          "wrong_password",
+			expectedStatus:    http.StatusForbidden,
+			expectedBody:      "Invalid username and/or password!\n",
+			expectedChallenge: `Basic realm="Private", charset="UTF-8"`,
+		},
+		{
+			name:              "test_missing_credentials",
+			expectedStatus:    http.StatusUnauthorized,
+			expectedBody:      "Invalid authentication scheme!\n",
+			expectedChallenge: `Basic realm="Private", charset="UTF-8"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := basic.New(basic.WithRealm("Private"), basic.WithUsers(map[string]string{"gerysantoso": "gerysantoso"}))
+			auth.InvalidCredentialsStatus = http.StatusForbidden
+
+			after := false
+			router := gin.New()
+			router.Use(Middleware(auth))
+			router.GET("/", func(c *gin.Context) {
+				username, _ := basic.UsernameFromContext(c.Request.Context())
+				c.String(http.StatusOK, "%s %s", c.GetString(UsernameKey), username)
+			}, func(c *gin.Context) {
+				after = true
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			if tc.username != "" {
+				r.SetBasicAuth(tc.username, tc.password)
+			}
+
+			router.ServeHTTP(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if body := w.Body.String(); body != tc.expectedBody {
+				t.Errorf("Expected and actual bodies are different! Expected: %q. Got: %q.", tc.expectedBody, body)
+			}
+
+			if challenge := w.Header().Get("WWW-Authenticate"); challenge != tc.expectedChallenge {
+				t.Errorf("Expected and actual challenges are different! Expected: %v. Got: %v.", tc.expectedChallenge, challenge)
+			}
+
+			if after != (tc.expectedStatus == http.StatusOK) {
+				t.Errorf("Expected the chain to only continue on success! Continued: %v.", after)
+			}
+		})
+	}
+}
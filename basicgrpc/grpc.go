@@ -0,0 +1,155 @@
+// Package basicgrpc adapts `github.com/lauslim12/basic` to gRPC servers, where the credentials are sent in the
+// `authorization` metadata, as in `Basic Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28=`. It is a module of its own, so the core
+// package does not depend on gRPC.
+//
+// The interceptors run the very same authentication as `Middleware`, so the authenticators, the lockout, the audit
+// trail, the metrics, and every other option of the `basic.BasicAuth` instance behave exactly as with HTTP. The
+// authenticated username is available in the handlers with `basic.UsernameFromContext`.
+package basicgrpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/lauslim12/basic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a unary interceptor authenticating calls with `a`. Failed authentications are
+// answered with `codes.Unauthenticated`, unless the call is forbidden (`codes.PermissionDenied`), malformed
+// (`codes.InvalidArgument`), or the authenticator fails (`codes.Internal`) or times out (`codes.Unavailable`). See
+// `code` for the full mapping of the HTTP status codes of the failure responses.
+func UnaryServerInterceptor(a *basic.BasicAuth) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, a, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor authenticating calls with `a`, with the same status codes as
+// `UnaryServerInterceptor`.
+func StreamServerInterceptor(a *basic.BasicAuth) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), a, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// serverStream is a `grpc.ServerStream` carrying the context of the authenticated call.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the authenticated call.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// statusRecorder is a `http.ResponseWriter` remembering the status code of a failed authentication.
+type statusRecorder struct {
+	header http.Header
+	code   int
+}
+
+// Header returns the headers of the response, which are discarded.
+func (w *statusRecorder) Header() http.Header {
+	return w.header
+}
+
+// Write discards the body of the response.
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	return len(b), nil
+}
+
+// WriteHeader remembers the status code of the response.
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+// authenticate runs the authentication of a call, and returns the context of the authenticated call, or the status
+// error to be returned to the client.
+func authenticate(ctx context.Context, a *basic.BasicAuth, fullMethod string) (context.Context, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, fullMethod, nil)
+	if err != nil {
+		return nil, status.Error(codes.Internal, http.StatusText(http.StatusInternalServerError))
+	}
+
+	// Metadata are sent as HTTP/2 headers, so they are passed on as such. Binary and reserved ones are skipped.
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		if strings.HasSuffix(key, "-bin") || strings.HasPrefix(key, ":") {
+			continue
+		}
+
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if p.Addr != nil {
+			r.RemoteAddr = p.Addr.String()
+		}
+
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			r.TLS = &info.State
+		}
+	}
+
+	var authenticated context.Context
+	w := &statusRecorder{header: make(http.Header)}
+	a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticated = r.Context()
+	})).ServeHTTP(w, r)
+
+	if authenticated != nil {
+		return authenticated, nil
+	}
+
+	// Custom failure responses may not write any status code, which would be sent as `200 OK` over HTTP.
+	message := http.StatusText(w.code)
+	if w.code == 0 || w.code == http.StatusOK {
+		message = http.StatusText(http.StatusUnauthorized)
+	}
+
+	return nil, status.Error(code(w.code), message)
+}
+
+// code converts the status code of a failed authentication to a gRPC status code, following the mapping between
+// HTTP and gRPC of the gRPC documentation. Calls refused whatever their credentials (such as with `RequireTLS` or
+// `ReadOnly`, or with `InvalidCredentialsStatus` set to `403 Forbidden`) are denied rather than unauthenticated.
+func code(status int) codes.Code {
+	switch status {
+	case http.StatusForbidden, http.StatusMethodNotAllowed:
+		return codes.PermissionDenied
+	case http.StatusBadRequest, http.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
+	case http.StatusInternalServerError:
+		return codes.Internal
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Unauthenticated
+	}
+}
@@ -0,0 +1,214 @@
+package basicgrpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/lauslim12/basic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// basicCredentials returns the value of the `authorization` metadata for a pair of credentials.
+func basicCredentials(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+// Tests the authentication of unary calls, and the username exposed to the handlers.
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name             string
+		authorization    string
+		configure        func(auth *basic.BasicAuth)
+		expectedCode     codes.Code
+		expectedUsername string
+	}{
+		{
+			name:             "test_valid_credentials",
+			authorization:    basicCredentials("gerysantoso", "gerysantoso"),
+			configure:        func(auth *basic.BasicAuth) {},
+			expectedCode:     codes.OK,
+			expectedUsername: "gerysantoso",
+		},
+		{
+			name:          "test_invalid_credentials",
+			authorization: basicCredentials("gerysantoso", "wrong_password"),
+			configure:     func(auth *basic.BasicAuth) {},
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:          "test_missing_credentials",
+			authorization: "",
+			configure:     func(auth *basic.BasicAuth) {},
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:          "test_other_scheme",
+			authorization: "Bearer token",
+			configure:     func(auth *basic.BasicAuth) {},
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:          "test_forbidden_invalid_credentials",
+			authorization: basicCredentials("gerysantoso", "wrong_password"),
+			configure: func(auth *basic.BasicAuth) {
+				auth.InvalidCredentialsStatus = http.StatusForbidden
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:          "test_insecure_transport",
+			authorization: basicCredentials("gerysantoso", "gerysantoso"),
+			configure: func(auth *basic.BasicAuth) {
+				auth.RequireTLS = true
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:          "test_read_only",
+			authorization: basicCredentials("gerysantoso", "gerysantoso"),
+			configure: func(auth *basic.BasicAuth) {
+				auth.ReadOnly = true
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:          "test_username_too_long",
+			authorization: basicCredentials("gerysantoso", "gerysantoso"),
+			configure: func(auth *basic.BasicAuth) {
+				auth.MaxUsernameLen = 4
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:          "test_unsupported_media_type",
+			authorization: basicCredentials("gerysantoso", "gerysantoso"),
+			configure: func(auth *basic.BasicAuth) {
+				auth.RequireContentTypes = []string{"application/json"}
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:          "test_authenticator_error",
+			authorization: basicCredentials("gerysantoso", "gerysantoso"),
+			configure: func(auth *basic.BasicAuth) {
+				auth.AuthenticatorE = func(username, password string) (bool, error) { return false, errors.New("backend is broken") }
+			},
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := basic.NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			tc.configure(auth)
+
+			md := metadata.Pairs("content-type", "application/grpc")
+			if tc.authorization != "" {
+				md.Set("authorization", tc.authorization)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			var username string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				username, _ = basic.UsernameFromContext(ctx)
+				return req, nil
+			}
+
+			info := &grpc.UnaryServerInfo{FullMethod: "/basic.Test/Unary"}
+			_, err := UnaryServerInterceptor(auth)(ctx, "request", info, handler)
+
+			if code := status.Code(err); code != tc.expectedCode {
+				t.Errorf("Expected and actual status codes are different! Expected: %v. Got: %v.", tc.expectedCode, code)
+			}
+
+			if username != tc.expectedUsername {
+				t.Errorf("Expected and actual usernames are different! Expected: %v. Got: %v.", tc.expectedUsername, username)
+			}
+		})
+	}
+}
+
+// Tests that a call whose context is done while the authenticator runs is answered with `codes.Unavailable`.
+func TestUnaryServerInterceptorCancelled(t *testing.T) {
+	auth := basic.NewDefaultBasicAuth(nil)
+	auth.AuthenticatorCtx = func(ctx context.Context, username, password string) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", basicCredentials("gerysantoso", "gerysantoso"))))
+	cancel()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/basic.Test/Unary"}
+	_, err := UnaryServerInterceptor(auth)(ctx, "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Error("Expected the handler not to be called.")
+		return nil, nil
+	})
+
+	if code := status.Code(err); code != codes.Unavailable {
+		t.Errorf("Expected and actual status codes are different! Expected: %v. Got: %v.", codes.Unavailable, code)
+	}
+}
+
+// testServerStream is a `grpc.ServerStream` only carrying a context.
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream.
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// Tests the authentication of streaming calls, and the username exposed to the handlers.
+func TestStreamServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name             string
+		password         string
+		expectedCode     codes.Code
+		expectedUsername string
+	}{
+		{
+			name:             "test_valid_credentials",
+			password:         "gerysantoso",
+			expectedCode:     codes.OK,
+			expectedUsername: "gerysantoso",
+		},
+		{
+			name:         "test_invalid_credentials",
+			password:     "wrong_password",
+			expectedCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := basic.NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", basicCredentials("gerysantoso", tc.password)))
+
+			var username string
+			handler := func(srv interface{}, stream grpc.ServerStream) error {
+				username, _ = basic.UsernameFromContext(stream.Context())
+				return nil
+			}
+
+			info := &grpc.StreamServerInfo{FullMethod: "/basic.Test/Stream", IsServerStream: true}
+			err := StreamServerInterceptor(auth)(nil, &testServerStream{ctx: ctx}, info, handler)
+
+			if code := status.Code(err); code != tc.expectedCode {
+				t.Errorf("Expected and actual status codes are different! Expected: %v. Got: %v.", tc.expectedCode, code)
+			}
+
+			if username != tc.expectedUsername {
+				t.Errorf("Expected and actual usernames are different! Expected: %v. Got: %v.", tc.expectedUsername, username)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Package basicprometheus exports the authentication decisions of `github.com/lauslim12/basic` as Prometheus
+// metrics. It is a module of its own, so the core package does not depend on the Prometheus client library.
+//
+// Outcomes are only labelled with their name (such as `success`, `invalid_scheme`, or `invalid_credentials`), which
+// is a small, fixed set of values. Usernames are never used as labels, so the cardinality stays bounded.
+package basicprometheus
+
+import (
+	"time"
+
+	"github.com/lauslim12/basic"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics is a `basic.Metrics` backed by Prometheus collectors.
+type Metrics struct {
+	results  *prometheus.CounterVec
+	duration prometheus.Histogram
+}
+
+// Ensure that `Metrics` can be plugged into `basic.BasicAuth`.
+var _ basic.Metrics = (*Metrics)(nil)
+
+// NewMetrics creates the collectors and registers them with `registerer`, or with `prometheus.DefaultRegisterer`
+// if it is `nil`. The following metrics are exported:
+//
+//   - `basic_auth_results_total`, a counter of authentication decisions, labelled by `result`.
+//   - `basic_auth_duration_seconds`, a histogram of the time taken to decide, including the authenticator.
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	m := &Metrics{
+		results: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "basic_auth_results_total",
+			Help: "Number of Basic Authentication decisions, by result.",
+		}, []string{"result"}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "basic_auth_duration_seconds",
+			Help:    "Time taken to decide on a Basic Authentication attempt, including the authenticator.",
+			Buckets: prometheus.DefBuckets,
+		}),
+	}
+
+	for _, collector := range []prometheus.Collector{m.results, m.duration} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// IncResult counts an authentication decision.
+func (m *Metrics) IncResult(result string) {
+	m.results.WithLabelValues(result).Inc()
+}
+
+// ObserveDuration records the time taken by an authentication decision.
+func (m *Metrics) ObserveDuration(d time.Duration) {
+	m.duration.Observe(d.Seconds())
+}
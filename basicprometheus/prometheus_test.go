@@ -0,0 +1,70 @@
+package basicprometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lauslim12/basic"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// Tests that authentication decisions are counted by result.
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := NewMetrics(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := basic.NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.Metrics = metrics
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	for _, header := range []string{
+		"Basic Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28=",
+		"Basic Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28=",
+		"Basic Z2VyeXNhbnRvc286d3Jvbmc=",
+		"Bearer token",
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", header)
+		handler(httptest.NewRecorder(), r)
+	}
+
+	tests := []struct {
+		name     string
+		result   string
+		expected float64
+	}{
+		{name: "test_success", result: "success", expected: 2},
+		{name: "test_invalid_credentials", result: "invalid_credentials", expected: 1},
+		{name: "test_invalid_scheme", result: "invalid_scheme", expected: 1},
+		{name: "test_missing_credentials", result: "missing_credentials", expected: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if count := testutil.ToFloat64(metrics.results.WithLabelValues(tc.result)); count != tc.expected {
+				t.Errorf("Expected and actual counts are different! Expected: %v. Got: %v.", tc.expected, count)
+			}
+		})
+	}
+
+	if count := testutil.CollectAndCount(metrics.duration); count != 1 {
+		t.Errorf("Expected and actual numbers of duration metrics are different! Expected: %v. Got: %v.", 1, count)
+	}
+}
+
+// Tests that registering the collectors twice fails.
+func TestNewMetricsDuplicate(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	if _, err := NewMetrics(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewMetrics(registry); err == nil {
+		t.Error("Expected an error when registering the collectors twice.")
+	}
+}
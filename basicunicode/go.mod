@@ -0,0 +1,8 @@
+module github.com/lauslim12/basic/basicunicode
+
+go 1.18
+
+require (
+	github.com/lauslim12/basic v1.1.0
+	golang.org/x/text v0.16.0
+)
@@ -0,0 +1,27 @@
+// Package basicunicode provides the Unicode normalization recommended by RFC 7617 for `github.com/lauslim12/basic`.
+// It is a separate module, so the core module does not require `golang.org/x/text`, which is only downloaded and
+// compiled by the programs importing this package.
+//
+// Two visually identical credentials can be encoded differently, such as an accented letter sent either composed
+// or decomposed by different clients. Normalizing them to NFC before they are compared makes them match.
+package basicunicode
+
+import (
+	"github.com/lauslim12/basic"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NFC normalizes a string to the Unicode Normalization Form C. It can be used as `UsernameUnicodeNormalizer` or
+// `PasswordUnicodeNormalizer`.
+func NFC(s string) string {
+	return norm.NFC.String(s)
+}
+
+// NormalizeUnicode normalizes both the usernames and the passwords of the default authenticator to NFC. The keys of
+// `Users` are expected to be normalized already (`Validate` reports the ones which are not), while plaintext
+// passwords of `Users` are normalized when they are compared. Hashed passwords have to be hashed after being
+// normalized. Normalization is not constant-time, so it runs before the constant-time comparison.
+func NormalizeUnicode(auth *basic.BasicAuth) {
+	auth.UsernameUnicodeNormalizer = NFC
+	auth.PasswordUnicodeNormalizer = NFC
+}
@@ -0,0 +1,84 @@
+package basicunicode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lauslim12/basic"
+)
+
+// Tests that composed and decomposed credentials only match with the normalization enabled.
+func TestNormalizeUnicode(t *testing.T) {
+	tests := []struct {
+		name           string
+		normalize      bool
+		username       string
+		password       string
+		expectedStatus int
+	}{
+		{
+			name:           "test_composed",
+			normalize:      true,
+			username:       "ren\u00e9",
+			password:       "caf\u00e9",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_decomposed_password",
+			normalize:      true,
+			username:       "ren\u00e9",
+			password:       "cafe\u0301",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_decomposed_username",
+			normalize:      true,
+			username:       "rene\u0301",
+			password:       "caf\u00e9",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_composed_without_normalization",
+			normalize:      false,
+			username:       "ren\u00e9",
+			password:       "caf\u00e9",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_invalid_password",
+			normalize:      true,
+			username:       "ren\u00e9",
+			password:       "cafe",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// The stored password is decomposed, so it has to be normalized as well.
+			auth := basic.NewDefaultBasicAuth(map[string]string{"ren\u00e9": "cafe\u0301"})
+			if tc.normalize {
+				NormalizeUnicode(auth)
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests the normalization of a string to NFC.
+func TestNFC(t *testing.T) {
+	if actual := NFC("cafe\u0301"); actual != "caf\u00e9" {
+		t.Errorf("Expected and actual normalized strings are different! Expected: %q. Got: %q.", "caf\u00e9", actual)
+	}
+}
@@ -0,0 +1,99 @@
+package basic
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Credential is a pair of a username and a password, as sent by a client.
+type Credential struct {
+	Username string
+	Password string
+}
+
+// VerifyBatch runs the authenticator over many credentials without constructing HTTP requests, which is handy for admin
+// tooling, migration validation, and integration tests. The result at each index tells whether the credential at the
+// same index is valid. Authenticators are picked in the same order of precedence as with requests.
+// `AuthenticatorWithRequest` is not used, as there is no request, `AuthenticatorCtx` and the authenticator set with
+// `SetAuthenticatorCtx` are called with the given context, and errors of `AuthenticatorE` or of the context-aware
+// authenticators are reported as invalid credentials. Credentials go through the same checks as with requests, such as
+// `DeniedUsers`, `DenyCredential`, the rejection of empty passwords, and the second factor of `UserTOTP`, except for
+// those which depend on the request itself. Locked out usernames are rejected unless `BatchBypassThrottling` is set,
+// and failed verifications are never counted toward a lockout. Second factors are checked without being recorded as
+// used, so they can still be used by the clients. Up to `BatchConcurrency` credentials are verified at the same time,
+// and the remaining ones are reported as invalid once the context is done. As an administrative check, it is neither
+// recorded in the audit trail nor counted in `Stats`.
+func (a *BasicAuth) VerifyBatch(ctx context.Context, creds []Credential) []bool {
+	var authenticator func(username, password string) (bool, error)
+	if fn, ok := contextAuthenticator(ctx); ok {
+		authenticator = infallible(fn)
+	} else if swapped, ok := a.loadSwapped(); ok {
+		authenticator = func(username, password string) (bool, error) {
+			return swapped.authenticator(ctx, username, password)
+		}
+	} else if a.AuthenticatorCtx != nil {
+		authenticator = func(username, password string) (bool, error) {
+			return a.AuthenticatorCtx(ctx, username, password)
+		}
+	} else {
+		authenticator = a.configuredAuthenticatorE()
+	}
+
+	limit := a.BatchConcurrency
+	if limit <= 0 {
+		limit = runtime.GOMAXPROCS(0)
+	}
+
+	// Each result is only written by its own goroutine, and read after all of them are done.
+	results := make([]bool, len(creds))
+	slots := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+dispatch:
+	for i := range creds {
+		// The context is checked first, as a select picks at random between its ready cases.
+		if ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			results[i] = a.verifyCredential(authenticator, creds[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// verifyCredential verifies a credential of `VerifyBatch` with the same steps as a request, except for those which
+// depend on the request itself.
+func (a *BasicAuth) verifyCredential(authenticator func(username, password string) (bool, error), cred Credential) bool {
+	username, password, code, res := a.screen(cred.Username, cred.Password, !a.BatchBypassThrottling)
+	if res != resultSuccess {
+		return false
+	}
+
+	if valid, err := authenticator(username, password); err != nil || !valid {
+		return false
+	}
+
+	if code == "" {
+		return true
+	}
+
+	_, ok := a.matchTOTP(username, code)
+
+	return ok
+}
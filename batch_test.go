@@ -0,0 +1,220 @@
+package basic
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Tests verifying a batch of credentials without HTTP requests.
+func TestVerifyBatch(t *testing.T) {
+	creds := []Credential{
+		{Username: "gerysantoso", Password: "gerysantoso"},
+		{Username: "gerysantoso", Password: "wrong"},
+		{Username: "unknown", Password: "gerysantoso"},
+		{Username: "nicholasdwiarto", Password: "nicholasdwiarto"},
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected []bool
+	}{
+		{
+			name:     "test_mixed_credentials",
+			ctx:      context.Background(),
+			expected: []bool{true, false, false, true},
+		},
+		{
+			name:     "test_context_authenticator",
+			ctx:      ContextWithAuthenticator(context.Background(), func(username, password string) bool { return username == "unknown" }),
+			expected: []bool{false, false, true, false},
+		},
+		{
+			name:     "test_canceled_context",
+			ctx:      canceled,
+			expected: []bool{false, false, false, false},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso", "nicholasdwiarto": "nicholasdwiarto"})
+
+			if results := auth.VerifyBatch(tc.ctx, creds); !reflect.DeepEqual(results, tc.expected) {
+				t.Errorf("Expected and actual results are different! Expected: %v. Got: %v.", tc.expected, results)
+			}
+
+			if stats := auth.Stats(); stats != (Stats{}) {
+				t.Errorf("Expected batch verifications not to be counted, but got: %+v.", stats)
+			}
+		})
+	}
+}
+
+// Tests that batch verifications go through the same checks as requests, and not only the authenticator.
+func TestVerifyBatchChecks(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1111111109, 0)
+	code := hotp(key, now.Unix()/totpPeriod, 6)
+	withTOTP := func(auth *BasicAuth) {
+		auth.UserTOTP = map[string]string{"gerysantoso": "gezd gnbv gy3t qojq gezd gnbv gy3t qojq"}
+	}
+	lockedOut := func(auth *BasicAuth) {
+		auth.MaxFailures = 1
+		auth.LockoutDuration = time.Minute
+		auth.recordFailure("gerysantoso")
+	}
+
+	tests := []struct {
+		name      string
+		configure func(auth *BasicAuth)
+		cred      Credential
+		expected  bool
+	}{
+		{
+			name:      "test_denied_user",
+			configure: func(auth *BasicAuth) { auth.DeniedUsers = []string{"gerysantoso"} },
+			cred:      Credential{Username: "gerysantoso", Password: "gerysantoso"},
+			expected:  false,
+		},
+		{
+			name:      "test_denied_credential",
+			configure: func(auth *BasicAuth) { auth.DenyCredential("gerysantoso", "gerysantoso") },
+			cred:      Credential{Username: "gerysantoso", Password: "gerysantoso"},
+			expected:  false,
+		},
+		{
+			name:      "test_empty_password",
+			configure: func(auth *BasicAuth) { auth.Users["legacy"] = "" },
+			cred:      Credential{Username: "legacy", Password: ""},
+			expected:  false,
+		},
+		{
+			name:      "test_empty_password_rejected_before_custom_authenticator",
+			configure: func(auth *BasicAuth) { auth.Authenticator = func(username, password string) bool { return true } },
+			cred:      Credential{Username: "gerysantoso", Password: ""},
+			expected:  false,
+		},
+		{
+			name:      "test_missing_second_factor",
+			configure: withTOTP,
+			cred:      Credential{Username: "gerysantoso", Password: "gerysantoso"},
+			expected:  false,
+		},
+		{
+			name:      "test_valid_second_factor",
+			configure: withTOTP,
+			cred:      Credential{Username: "gerysantoso", Password: "gerysantoso" + code},
+			expected:  true,
+		},
+		{
+			name:      "test_invalid_second_factor",
+			configure: withTOTP,
+			cred:      Credential{Username: "gerysantoso", Password: "gerysantoso000000"},
+			expected:  false,
+		},
+		{
+			name:      "test_locked_out_user",
+			configure: lockedOut,
+			cred:      Credential{Username: "gerysantoso", Password: "gerysantoso"},
+			expected:  false,
+		},
+		{
+			name: "test_locked_out_user_bypassed",
+			configure: func(auth *BasicAuth) {
+				lockedOut(auth)
+				auth.BatchBypassThrottling = true
+			},
+			cred:     Credential{Username: "gerysantoso", Password: "gerysantoso"},
+			expected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			auth.clock = func() time.Time { return now }
+			tc.configure(auth)
+
+			if results := auth.VerifyBatch(context.Background(), []Credential{tc.cred}); results[0] != tc.expected {
+				t.Errorf("Expected and actual results are different! Expected: %v. Got: %v.", tc.expected, results[0])
+			}
+		})
+	}
+}
+
+// Tests that batch verifications neither count toward a lockout nor use up the second factor of the clients.
+func TestVerifyBatchSideEffects(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1111111109, 0)
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.clock = func() time.Time { return now }
+	auth.MaxFailures = 2
+	auth.LockoutDuration = time.Minute
+	auth.TOTPSkew = 1
+	auth.UserTOTP = map[string]string{"gerysantoso": "gezd gnbv gy3t qojq gezd gnbv gy3t qojq"}
+
+	creds := []Credential{
+		{Username: "gerysantoso", Password: "gerysantoso000000"},
+		{Username: "gerysantoso", Password: "gerysantoso000000"},
+		{Username: "gerysantoso", Password: "gerysantoso" + hotp(key, now.Unix()/totpPeriod, 6)},
+	}
+	if results := auth.VerifyBatch(context.Background(), creds); !reflect.DeepEqual(results, []bool{false, false, true}) {
+		t.Errorf("Expected and actual results are different! Expected: %v. Got: %v.", []bool{false, false, true}, results)
+	}
+
+	if auth.isLockedOut("gerysantoso") {
+		t.Errorf("Expected batch verifications not to lock the user out.")
+	}
+
+	// A code of the previous step is only accepted if the code of the current step has not been used.
+	if res := auth.verifyTOTP("gerysantoso", "gerysantoso", hotp(key, now.Unix()/totpPeriod-1, 6)); res != resultSuccess {
+		t.Errorf("Expected and actual TOTP results are different! Expected: %v. Got: %v.", resultSuccess, res)
+	}
+}
+
+// Tests that no more than `BatchConcurrency` credentials are verified at the same time.
+func TestVerifyBatchConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	var running, peak int
+
+	auth := NewDefaultBasicAuth(nil)
+	auth.BatchConcurrency = 2
+	auth.Authenticator = func(username, password string) bool {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		return username == password
+	}
+
+	creds := make([]Credential, 8)
+	expected := make([]bool, len(creds))
+	for i := range creds {
+		creds[i] = Credential{Username: "gerysantoso", Password: "gerysantoso"}
+		expected[i] = true
+	}
+
+	if results := auth.VerifyBatch(context.Background(), creds); !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected and actual results are different! Expected: %v. Got: %v.", expected, results)
+	}
+
+	if peak != auth.BatchConcurrency {
+		t.Errorf("Expected and actual concurrent verifications are different! Expected: %v. Got: %v.", auth.BatchConcurrency, peak)
+	}
+}
@@ -0,0 +1,26 @@
+package basic
+
+import "net/http"
+
+// bearerToken returns the token of a request using the `Bearer` scheme (RFC 6750) instead of `Basic`, if
+// `BearerFallback` is set. The scheme is tokenized as with `Basic`, so it is case-insensitive, and may be followed by
+// spaces or tabs.
+func (a *BasicAuth) bearerToken(r *http.Request) (string, bool) {
+	if a.BearerFallback == nil {
+		return "", false
+	}
+
+	token, ok := cutScheme(a.authorizationHeader(r), "Bearer")
+
+	return token, ok && token != ""
+}
+
+// bearerChallenge returns the `Bearer` challenge advertised next to the `Basic` one if `BearerFallback` is set, or
+// an empty string otherwise.
+func (a *BasicAuth) bearerChallenge(realm string) string {
+	if a.BearerFallback == nil {
+		return ""
+	}
+
+	return ", Bearer realm=" + quoteString(realm)
+}
@@ -0,0 +1,160 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Tests that Bearer tokens are only accepted with `BearerFallback`, next to Basic credentials.
+func TestBearerFallback(t *testing.T) {
+	fallback := func(token string) bool { return CompareInputs(token, "secret_token") }
+
+	tests := []struct {
+		name           string
+		fallback       func(token string) bool
+		header         string
+		expectedStatus int
+	}{
+		{
+			name:           "test_valid_token",
+			fallback:       fallback,
+			header:         "Bearer secret_token",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_case_insensitive_scheme",
+			fallback:       fallback,
+			header:         "bearer secret_token",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_tab_after_scheme",
+			fallback:       fallback,
+			header:         "Bearer\tsecret_token",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_extra_white_space",
+			fallback:       fallback,
+			header:         "Bearer \t secret_token ",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_missing_separator",
+			fallback:       fallback,
+			header:         "Bearersecret_token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_invalid_token",
+			fallback:       fallback,
+			header:         "Bearer wrong_token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_empty_token",
+			fallback:       fallback,
+			header:         "Bearer ",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_basic_still_accepted",
+			fallback:       fallback,
+			header:         "Basic Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28=",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_without_fallback",
+			fallback:       nil,
+			header:         "Bearer secret_token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Test", map[string]string{"gerysantoso": "gerysantoso"})
+			auth.BearerFallback = tc.fallback
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.Header.Set("Authorization", tc.header)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests that rejected Bearer tokens are not counted as failures of the empty username.
+func TestBearerFallbackLockout(t *testing.T) {
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.BearerFallback = func(token string) bool { return CompareInputs(token, "secret_token") }
+	auth.MaxFailures = 2
+	auth.LockoutDuration = time.Minute
+
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	request := func(header string) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		r.Header.Set("Authorization", header)
+		handler(w, r)
+
+		return w.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		request("Bearer wrong_token")
+	}
+
+	if auth.isLockedOut("") {
+		t.Errorf("Expected the empty username not to be locked out by rejected Bearer tokens!")
+	}
+
+	if code := request("Bearer secret_token"); code != http.StatusOK {
+		t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusOK, code)
+	}
+}
+
+// Tests that the challenge advertises both schemes with `BearerFallback`.
+func TestBearerChallenge(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback func(token string) bool
+		expected string
+	}{
+		{
+			name:     "test_with_fallback",
+			fallback: func(token string) bool { return false },
+			expected: `Basic realm="Test", charset="UTF-8", Bearer realm="Test"`,
+		},
+		{
+			name:     "test_without_fallback",
+			fallback: nil,
+			expected: `Basic realm="Test", charset="UTF-8"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Test", nil)
+			auth.BearerFallback = tc.fallback
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+
+			handler(w, r)
+
+			if challenge := w.Header().Get("WWW-Authenticate"); challenge != tc.expected {
+				t.Errorf("Expected and actual challenges are different! Expected: %v. Got: %v.", tc.expected, challenge)
+			}
+		})
+	}
+}
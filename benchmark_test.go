@@ -0,0 +1,132 @@
+package basic
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// benchmarkAuthenticate measures the throughput of `Authenticate` under concurrent load, and reports the
+// 99th percentile latency of a single request as a custom metric.
+func benchmarkAuthenticate(b *testing.B, stored string, verifier PasswordVerifier) {
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": stored})
+	auth.PasswordVerifier = verifier
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	var mu sync.Mutex
+	latencies := make([]time.Duration, 0, b.N)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		local := make([]time.Duration, 0, 64)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.SetBasicAuth("gerysantoso", "gerysantoso")
+
+		for pb.Next() {
+			w := httptest.NewRecorder()
+			start := time.Now()
+			handler(w, r)
+			local = append(local, time.Since(start))
+
+			if w.Code != http.StatusOK {
+				b.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusOK, w.Code)
+			}
+		}
+
+		mu.Lock()
+		latencies = append(latencies, local...)
+		mu.Unlock()
+	})
+	b.StopTimer()
+
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		b.ReportMetric(float64(latencies[len(latencies)*99/100].Nanoseconds()), "p99-ns/op")
+	}
+}
+
+// Benchmarks the default verifier, where users are stored as plaintext passwords. The bcrypt and Argon2id verifiers
+// are benchmarked in `basicbcrypt`, so the core module does not require `golang.org/x/crypto`.
+func BenchmarkAuth_Plaintext(b *testing.B) {
+	benchmarkAuthenticate(b, "gerysantoso", nil)
+}
+
+// Benchmarks the parsing of the `Authorization` header, which runs on every request.
+func BenchmarkParseBasicAuth(b *testing.B) {
+	header := "Basic " + base64.StdEncoding.EncodeToString([]byte("gerysantoso:gerysantoso"))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, ok := parseBasicAuth(header, base64.StdEncoding); !ok {
+			b.Fatal("Expected the header to be parsed.")
+		}
+	}
+}
+
+// Benchmarks the comparison of a password with SHA-256 digests, for a short password and for one longer than a
+// SHA-256 block.
+func BenchmarkCompareInputs(b *testing.B) {
+	tests := []struct {
+		name     string
+		password string
+	}{
+		{name: "short_password", password: "gerysantoso"},
+		{name: "long_password", password: strings.Repeat("gerysantoso", 20)},
+	}
+
+	for _, tc := range tests {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				CompareInputs(tc.password, tc.password)
+			}
+		})
+	}
+}
+
+// Benchmarks the comparison of a password with HMAC-SHA256 under the process key.
+func BenchmarkCompareInputsHMAC(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		CompareInputsHMAC("gerysantoso", "gerysantoso")
+	}
+}
+
+// Benchmarks a single request through `Authenticate` with the default authenticator, for both an existing and a
+// missing user, without the concurrency of `benchmarkAuthenticate`.
+func BenchmarkAuthenticate(b *testing.B) {
+	tests := []struct {
+		name     string
+		username string
+		expected int
+	}{
+		{name: "existing_user", username: "gerysantoso", expected: http.StatusOK},
+		{name: "missing_user", username: "nicholasdwiarto", expected: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		b.Run(tc.name, func(b *testing.B) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.SetBasicAuth(tc.username, "gerysantoso")
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				w := httptest.NewRecorder()
+				handler(w, r)
+
+				if w.Code != tc.expected {
+					b.Fatalf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expected, w.Code)
+				}
+			}
+		})
+	}
+}
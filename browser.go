@@ -0,0 +1,52 @@
+package basic
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// sendChallenge sends a failure response carrying a challenge, usually the one of `challenge`. Browsers are served
+// `BrowserLoginPage` if set, and everyone else is served the given handler.
+//
+// The login page is sent with a `401 Unauthorized` status and the `WWW-Authenticate` header, so browsers still
+// show their native login dialog first, and only render the page once the dialog is dismissed (for example,
+// with a sign-in link, or instructions to retry). Credentials entered in the dialog are sent as usual.
+func (a *BasicAuth) sendChallenge(w http.ResponseWriter, r *http.Request, handler http.Handler, challenge string) {
+	a.stripHeaders(w)
+	if challenge != "" {
+		w.Header().Set("WWW-Authenticate", challenge)
+		if link := a.tokenEndpointLink(); link != "" {
+			w.Header().Add("Link", link)
+		}
+	}
+
+	if a.BrowserLoginPage == nil || !acceptsHTML(r) {
+		handler.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusUnauthorized)
+
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(a.BrowserLoginPage)
+	}
+}
+
+// acceptsHTML checks whether the `Accept` header of a request explicitly lists `text/html`, which is the case
+// for browser navigations, but not for API clients. Wildcards are ignored on purpose, as most API clients send
+// `*/*` by default.
+func acceptsHTML(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(part)
+			if err == nil && mediaType == "text/html" && params["q"] != "0" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
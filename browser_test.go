@@ -0,0 +1,109 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests that browsers are served `BrowserLoginPage`, while API clients still get the configured responses.
+func TestBrowserLoginPage(t *testing.T) {
+	page := []byte("<!doctype html><title>Sign in</title>")
+	tests := []struct {
+		name           string
+		method         string
+		accept         string
+		withLoginPage  bool
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+	}{
+		{
+			name:           "test_html_client",
+			method:         http.MethodGet,
+			accept:         "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			withLoginPage:  true,
+			expectedStatus: http.StatusUnauthorized,
+			expectedType:   "text/html; charset=utf-8",
+			expectedBody:   string(page),
+		},
+		{
+			name:           "test_html_client_head",
+			method:         http.MethodHead,
+			accept:         "text/html",
+			withLoginPage:  true,
+			expectedStatus: http.StatusUnauthorized,
+			expectedType:   "text/html; charset=utf-8",
+			expectedBody:   "",
+		},
+		{
+			name:           "test_json_client",
+			method:         http.MethodGet,
+			accept:         "application/json",
+			withLoginPage:  true,
+			expectedStatus: http.StatusUnauthorized,
+			expectedType:   "text/plain; charset=utf-8",
+			expectedBody:   invalidCredentialsMessage + "\n",
+		},
+		{
+			name:           "test_wildcard_client",
+			method:         http.MethodGet,
+			accept:         "*/*",
+			withLoginPage:  true,
+			expectedStatus: http.StatusUnauthorized,
+			expectedType:   "text/plain; charset=utf-8",
+			expectedBody:   invalidCredentialsMessage + "\n",
+		},
+		{
+			name:           "test_html_refused",
+			method:         http.MethodGet,
+			accept:         "text/html;q=0, application/json",
+			withLoginPage:  true,
+			expectedStatus: http.StatusUnauthorized,
+			expectedType:   "text/plain; charset=utf-8",
+			expectedBody:   invalidCredentialsMessage + "\n",
+		},
+		{
+			name:           "test_html_client_without_login_page",
+			method:         http.MethodGet,
+			accept:         "text/html",
+			withLoginPage:  false,
+			expectedStatus: http.StatusUnauthorized,
+			expectedType:   "text/plain; charset=utf-8",
+			expectedBody:   invalidCredentialsMessage + "\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			auth.Realm = "Private"
+			if tc.withLoginPage {
+				auth.BrowserLoginPage = page
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(tc.method, "/", nil)
+			w := httptest.NewRecorder()
+			r.Header.Set("Accept", tc.accept)
+			r.SetBasicAuth("gerysantoso", "wrong")
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if w.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("Expected a challenge in the 'WWW-Authenticate' header, but got none.")
+			}
+
+			if contentType := w.Header().Get("Content-Type"); contentType != tc.expectedType {
+				t.Errorf("Expected and actual content types are different! Expected: %v. Got: %v.", tc.expectedType, contentType)
+			}
+
+			if body := w.Body.String(); body != tc.expectedBody {
+				t.Errorf("Expected and actual bodies are different! Expected: %q. Got: %q.", tc.expectedBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package basic
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultCacheSize is the number of successful authentications remembered by `CacheTTL` if `CacheSize` is not set.
+const defaultCacheSize = 1024
+
+// cachedAuthentication is a successful authentication remembered by `CacheTTL`, with the generation of the
+// authenticator and the version of the credentials of the user at the time of the verification.
+type cachedAuthentication struct {
+	key        string
+	generation uint32
+	version    string
+	expires    time.Time
+}
+
+// resultCache is a bounded cache of successful authentications, keyed by the keyed hashes of the credentials. Once
+// it is full, the least recently used authentications are evicted first.
+type resultCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   list.List // Most recently used first.
+}
+
+// get checks whether an authentication is cached, and still valid for the current generation, version, and time.
+// Stale authentications are evicted.
+func (c *resultCache) get(current cachedAuthentication, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[current.key]
+	if !ok {
+		return false
+	}
+
+	entry := element.Value.(cachedAuthentication)
+	if entry.generation != current.generation || entry.version != current.version || !now.Before(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, entry.key)
+		return false
+	}
+
+	c.order.MoveToFront(element)
+
+	return true
+}
+
+// add caches a successful authentication, evicting the least recently used ones beyond `size`.
+func (c *resultCache) add(entry cachedAuthentication, size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+	}
+
+	if element, ok := c.entries[entry.key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(cachedAuthentication).key)
+	}
+}
+
+// ClearCache forgets all the successful authentications cached with `CacheTTL`, so the next requests are verified
+// by the authenticator again. This is useful to revoke a user immediately. `AddUser`, `RemoveUser`,
+// `SetAuthenticator`, and `SetAuthenticatorCtx` already invalidate the cache.
+func (a *BasicAuth) ClearCache() {
+	a.results.mu.Lock()
+	defer a.results.mu.Unlock()
+
+	a.results.entries = nil
+	a.results.order.Init()
+}
+
+// cachedAuthenticator wraps an authenticator, so successful authentications are remembered for `CacheTTL`, and
+// requests with the same credentials skip it in the meantime. Failures and errors are never cached.
+func (a *BasicAuth) cachedAuthenticator(authenticator func(username, password string) (bool, error)) func(username, password string) (bool, error) {
+	return func(username, password string) (bool, error) {
+		// The generation and the version are read before verifying, so a rotation during the verification is not
+		// remembered as verified.
+		now := a.now()
+		current := cachedAuthentication{
+			key:        credentialsKey(username, password),
+			generation: atomic.LoadUint32(&a.generation),
+			version:    a.credentialVersion(username),
+			expires:    now.Add(a.CacheTTL),
+		}
+
+		if a.results.get(current, now) {
+			return true, nil
+		}
+
+		ok, err := authenticator(username, password)
+		if err == nil && ok {
+			a.results.add(current, a.cacheSize())
+		}
+
+		return ok, err
+	}
+}
+
+// cacheSize returns the maximum number of cached authentications.
+func (a *BasicAuth) cacheSize() int {
+	if a.CacheSize <= 0 {
+		return defaultCacheSize
+	}
+
+	return a.CacheSize
+}
@@ -0,0 +1,110 @@
+package basic
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Tests that `CacheTTL` only skips the authenticator for credentials which recently succeeded.
+func TestCacheTTL(t *testing.T) {
+	var calls int32
+	now := time.Now()
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso", "nicholasdwiarto": "nicholasdwiarto", "lauslim12": "lauslim12"})
+	auth.CacheTTL = time.Minute
+	auth.CacheSize = 2
+	auth.clock = func() time.Time { return now }
+	auth.Authenticator = func(username, password string) bool {
+		atomic.AddInt32(&calls, 1)
+		return auth.verifyUser(username, password)
+	}
+
+	steps := []struct {
+		name           string
+		username       string
+		password       string
+		configure      func()
+		expectedStatus int
+		expectedCalls  int32
+	}{
+		{name: "test_first_request", username: "gerysantoso", password: "gerysantoso", expectedStatus: http.StatusOK, expectedCalls: 1},
+		{name: "test_cached", username: "gerysantoso", password: "gerysantoso", expectedStatus: http.StatusOK, expectedCalls: 1},
+		{name: "test_failure_not_cached", username: "gerysantoso", password: "wrong", expectedStatus: http.StatusUnauthorized, expectedCalls: 2},
+		{name: "test_failure_verified_again", username: "gerysantoso", password: "wrong", expectedStatus: http.StatusUnauthorized, expectedCalls: 3},
+		{name: "test_within_ttl", username: "gerysantoso", password: "gerysantoso", configure: func() { now = now.Add(59 * time.Second) }, expectedStatus: http.StatusOK, expectedCalls: 3},
+		{name: "test_expired", username: "gerysantoso", password: "gerysantoso", configure: func() { now = now.Add(time.Second) }, expectedStatus: http.StatusOK, expectedCalls: 4},
+		{name: "test_second_user", username: "nicholasdwiarto", password: "nicholasdwiarto", expectedStatus: http.StatusOK, expectedCalls: 5},
+		{name: "test_first_user_used_again", username: "gerysantoso", password: "gerysantoso", expectedStatus: http.StatusOK, expectedCalls: 5},
+		{name: "test_third_user_evicts_second", username: "lauslim12", password: "lauslim12", expectedStatus: http.StatusOK, expectedCalls: 6},
+		{name: "test_most_recently_used_kept", username: "gerysantoso", password: "gerysantoso", expectedStatus: http.StatusOK, expectedCalls: 6},
+		{name: "test_least_recently_used_evicted", username: "nicholasdwiarto", password: "nicholasdwiarto", expectedStatus: http.StatusOK, expectedCalls: 7},
+		{name: "test_cleared", username: "nicholasdwiarto", password: "nicholasdwiarto", configure: auth.ClearCache, expectedStatus: http.StatusOK, expectedCalls: 8},
+		{name: "test_rotated_password", username: "nicholasdwiarto", password: "nicholasdwiarto", configure: func() { auth.AddUser("nicholasdwiarto", "rotated") }, expectedStatus: http.StatusUnauthorized, expectedCalls: 9},
+		{name: "test_removed_user", username: "lauslim12", password: "lauslim12", configure: func() { auth.RemoveUser("lauslim12") }, expectedStatus: http.StatusUnauthorized, expectedCalls: 10},
+	}
+
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	for _, step := range steps {
+		if step.configure != nil {
+			step.configure()
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		r.SetBasicAuth(step.username, step.password)
+
+		handler(w, r)
+
+		if w.Code != step.expectedStatus {
+			t.Errorf("%s: Expected and actual status code values are different! Expected: %v. Got: %v.", step.name, step.expectedStatus, w.Code)
+		}
+
+		if actual := atomic.LoadInt32(&calls); actual != step.expectedCalls {
+			t.Errorf("%s: Expected and actual authenticator calls are different! Expected: %v. Got: %v.", step.name, step.expectedCalls, actual)
+		}
+	}
+}
+
+// Tests that the cache stays bounded and consistent under concurrent requests.
+func TestCacheTTLConcurrent(t *testing.T) {
+	users := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		users[fmt.Sprintf("user%d", i)] = fmt.Sprintf("password%d", i)
+	}
+
+	auth := NewDefaultBasicAuth(users)
+	auth.CacheTTL = time.Minute
+	auth.CacheSize = 10
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(fmt.Sprintf("user%d", i%50), fmt.Sprintf("password%d", i%50))
+
+			handler(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusOK, w.Code)
+			}
+
+			if i%20 == 0 {
+				auth.ClearCache()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if size := len(auth.results.entries); size > auth.CacheSize || size != auth.results.order.Len() {
+		t.Errorf("Expected the cache to stay bounded! Entries: %v. Order: %v.", size, auth.results.order.Len())
+	}
+}
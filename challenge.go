@@ -0,0 +1,194 @@
+package basic
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxChallengedClients is the maximum number of clients remembered by `ChallengeFirstWindow` or `IdleTimeout`.
+const maxChallengedClients = 10000
+
+// ChallengeFor returns the challenge that would be sent to a request failing the authentication, without sending any
+// response, or an empty string if no challenge would be sent. The challenge is sent in `WWW-Authenticate`, or in
+// `Proxy-Authenticate` with `ProxyMode`. This is useful for clients reacting to challenges, and for tests.
+func (a *BasicAuth) ChallengeFor(r *http.Request) string {
+	return a.challengeRealm(a.realmFor(r))
+}
+
+// challenge returns the value of the `WWW-Authenticate` header with the static `Realm`, or an empty string if it
+// should not be sent.
+func (a *BasicAuth) challenge() string {
+	return a.challengeRealm(a.Realm)
+}
+
+// realmFor returns the realm of a request, which is the one returned by `RealmFunc` if set and not empty, or the
+// static `Realm` otherwise.
+func (a *BasicAuth) realmFor(r *http.Request) string {
+	if a.RealmFunc != nil {
+		if realm := a.RealmFunc(r); realm != "" {
+			return realm
+		}
+	}
+
+	return a.Realm
+}
+
+// challengeRealm returns the value of the `WWW-Authenticate` header for a realm other than `Realm`, or an empty
+// string if it should not be sent.
+func (a *BasicAuth) challengeRealm(realm string) string {
+	if realm == "" || a.Charset == "" {
+		return ""
+	}
+
+	// RFC 7617 only allows `UTF-8` as the charset, so legacy ISO-8859-1 clients are not told about it. The realm and
+	// any other charset are quoted, so misconfigured ones cannot break out of the header.
+	var sb strings.Builder
+	if isLatin1(a.Charset) {
+		fmt.Fprintf(&sb, "Basic realm=%s", quoteString(realm))
+	} else {
+		fmt.Fprintf(&sb, "Basic realm=%s, charset=%s", quoteString(realm), quoteString(a.Charset))
+	}
+
+	// Extra auth-params are sorted, so the challenge is deterministic.
+	names := make([]string, 0, len(a.ChallengeParams))
+	for name := range a.ChallengeParams {
+		if isToken(name) && !strings.EqualFold(name, "realm") && !strings.EqualFold(name, "charset") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(&sb, ", %s=%s", name, quoteString(a.ChallengeParams[name]))
+	}
+	sb.WriteString(a.bearerChallenge(realm))
+
+	return sb.String()
+}
+
+// quoteString formats a value as a quoted-string (RFC 7230). Double quotes and backslashes are escaped, and
+// control characters are removed, so the value cannot break out of the header.
+func quoteString(value string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, c := range value {
+		switch {
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(c)
+		case c < ' ' || c == 0x7f:
+			continue
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	sb.WriteByte('"')
+
+	return sb.String()
+}
+
+// tokenEndpointLink returns the value of the `Link` header pointing clients to `TokenEndpoint` (RFC 8288), or an
+// empty string if it is not set or is not a valid URI reference.
+func (a *BasicAuth) tokenEndpointLink() string {
+	if a.TokenEndpoint == "" {
+		return ""
+	}
+
+	target, err := linkTarget(a.TokenEndpoint)
+	if err != nil {
+		return ""
+	}
+
+	return "<" + target + `>; rel="token-endpoint"`
+}
+
+// linkTarget parses a URI reference, and percent-encodes the characters that are not allowed in a URI (RFC 3986),
+// so it cannot break out of the angle brackets of a `Link` header.
+func linkTarget(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	target := u.String()
+	var sb strings.Builder
+	for i := 0; i < len(target); i++ {
+		if c := target[i]; c > ' ' && c < 0x7f && !strings.ContainsRune("\"<>\\^`{|}", rune(c)) {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// isToken checks whether a value is a valid token (RFC 7230), which is required for the names of auth-params.
+func isToken(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for _, c := range value {
+		if c > '~' || c <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// challengeFirst checks whether the request is the first one of its client within `ChallengeFirstWindow`. If it
+// is, the client is marked, and the request has to be challenged regardless of its credentials. The marker is kept
+// per client IP in the memory of this instance, so clients behind the same NAT share it, and it is lost on restart.
+func (a *BasicAuth) challengeFirst(ip string) bool {
+	if a.ChallengeFirstWindow <= 0 {
+		return false
+	}
+
+	now := a.now()
+
+	a.challengedMu.Lock()
+	defer a.challengedMu.Unlock()
+
+	if seen, ok := a.challenged[ip]; ok && now.Sub(seen) < a.ChallengeFirstWindow {
+		return false
+	}
+
+	if a.challenged == nil {
+		a.challenged = make(map[string]time.Time)
+	}
+
+	makeClientRoom(a.challenged, now, a.ChallengeFirstWindow)
+	a.challenged[ip] = now
+
+	return true
+}
+
+// makeClientRoom keeps the markers of clients bounded, as `makeLockoutRoom` does for the lockouts. Once there are
+// too many, the markers older than `ttl` are purged, and if there are still too many, random markers are evicted
+// until a tenth of the room is free again, so the purge does not run on every new client of an attacker rotating
+// addresses. Must be called with the lock guarding the markers held.
+func makeClientRoom(clients map[string]time.Time, now time.Time, ttl time.Duration) {
+	if len(clients) < maxChallengedClients {
+		return
+	}
+
+	for client, seen := range clients {
+		if now.Sub(seen) >= ttl {
+			delete(clients, client)
+		}
+	}
+
+	for client := range clients {
+		if len(clients) < maxChallengedClients-maxChallengedClients/10 {
+			return
+		}
+
+		delete(clients, client)
+	}
+}
@@ -0,0 +1,460 @@
+package basic
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests that `RealmFunc` picks the realm of each request, with the static realm as the fallback.
+func TestRealmFunc(t *testing.T) {
+	realmFunc := func(r *http.Request) string {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/admin"):
+			return "Admin"
+		case strings.HasPrefix(r.URL.Path, "/api"):
+			return "API"
+		default:
+			return ""
+		}
+	}
+
+	tests := []struct {
+		name              string
+		realmFunc         func(r *http.Request) string
+		path              string
+		expectedChallenge string
+		expectedRealm     string
+	}{
+		{
+			name:              "test_admin_realm",
+			realmFunc:         realmFunc,
+			path:              "/admin/users",
+			expectedChallenge: `Basic realm="Admin", charset="UTF-8"`,
+			expectedRealm:     "Admin",
+		},
+		{
+			name:              "test_api_realm",
+			realmFunc:         realmFunc,
+			path:              "/api/v1",
+			expectedChallenge: `Basic realm="API", charset="UTF-8"`,
+			expectedRealm:     "API",
+		},
+		{
+			name:              "test_empty_realm_falls_back",
+			realmFunc:         realmFunc,
+			path:              "/",
+			expectedChallenge: `Basic realm="Default", charset="UTF-8"`,
+			expectedRealm:     "Default",
+		},
+		{
+			name:              "test_escaped_realm",
+			realmFunc:         func(r *http.Request) string { return "a\"b\r\nX-Injected: 1" },
+			path:              "/",
+			expectedChallenge: `Basic realm="a\"bX-Injected: 1", charset="UTF-8"`,
+			expectedRealm:     "a\"b\r\nX-Injected: 1",
+		},
+		{
+			name:              "test_static_realm",
+			realmFunc:         nil,
+			path:              "/admin",
+			expectedChallenge: `Basic realm="Default", charset="UTF-8"`,
+			expectedRealm:     "Default",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var event AuthEvent
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Default", nil)
+			auth.RealmFunc = tc.realmFunc
+			auth.Logger = func(e AuthEvent) { event = e }
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			w := httptest.NewRecorder()
+
+			if challenge := auth.ChallengeFor(r); challenge != tc.expectedChallenge {
+				t.Errorf("Expected and actual challenges are different! Expected: %v. Got: %v.", tc.expectedChallenge, challenge)
+			}
+
+			handler(w, r)
+
+			if challenge := w.Header().Get("WWW-Authenticate"); challenge != tc.expectedChallenge {
+				t.Errorf("Expected and actual sent challenges are different! Expected: %v. Got: %v.", tc.expectedChallenge, challenge)
+			}
+
+			if event.Realm != tc.expectedRealm {
+				t.Errorf("Expected and actual realms of the event are different! Expected: %v. Got: %v.", tc.expectedRealm, event.Realm)
+			}
+		})
+	}
+}
+
+// Tests that the realm is escaped by `SetWWWAuthenticate`, so a malicious one cannot break or inject headers.
+func TestSetWWWAuthenticateRealm(t *testing.T) {
+	tests := []struct {
+		name     string
+		realm    string
+		expected string
+	}{
+		{
+			name:     "test_plain_realm",
+			realm:    "Private",
+			expected: `Basic realm="Private", charset="UTF-8"`,
+		},
+		{
+			name:     "test_double_quote",
+			realm:    `a"bc`,
+			expected: `Basic realm="a\"bc", charset="UTF-8"`,
+		},
+		{
+			name:     "test_backslash",
+			realm:    `a\bc`,
+			expected: `Basic realm="a\\bc", charset="UTF-8"`,
+		},
+		{
+			name:     "test_crlf_injection",
+			realm:    "a\r\nX-Injected: 1",
+			expected: `Basic realm="aX-Injected: 1", charset="UTF-8"`,
+		},
+		{
+			name:     "test_realm_breaking_out",
+			realm:    `a", charset="ISO-8859-1`,
+			expected: `Basic realm="a\", charset=\"ISO-8859-1", charset="UTF-8"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, tc.realm, nil)
+			w := httptest.NewRecorder()
+
+			auth.SetWWWAuthenticate(w)
+
+			if header := w.Header().Get("WWW-Authenticate"); header != tc.expected {
+				t.Errorf("Expected and actual challenges are different! Expected: %v. Got: %v.", tc.expected, header)
+			}
+
+			if header := w.Header().Get("WWW-Authenticate"); strings.ContainsAny(header, "\r\n") {
+				t.Errorf("Expected the challenge to stay on a single line! Got: %q.", header)
+			}
+		})
+	}
+}
+
+// Tests that the charset is quoted in the challenge, so a misconfigured one cannot break the header.
+func TestChallengeCharset(t *testing.T) {
+	tests := []struct {
+		name     string
+		charset  string
+		expected string
+	}{
+		{
+			name:     "test_utf8",
+			charset:  "UTF-8",
+			expected: `Basic realm="Test", charset="UTF-8"`,
+		},
+		{
+			name:     "test_latin1_omitted",
+			charset:  "ISO-8859-1",
+			expected: `Basic realm="Test"`,
+		},
+		{
+			name:     "test_escaped_quotes",
+			charset:  `UTF-8", realm="Other`,
+			expected: `Basic realm="Test", charset="UTF-8\", realm=\"Other"`,
+		},
+		{
+			name:     "test_control_characters_removed",
+			charset:  "UTF-8\r\nX-Injected: 1",
+			expected: `Basic realm="Test", charset="UTF-8X-Injected: 1"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, tc.charset, nil, nil, "Test", nil)
+
+			if challenge := auth.challenge(); challenge != tc.expected {
+				t.Errorf("Expected and actual challenges are different! Expected: %v. Got: %v.", tc.expected, challenge)
+			}
+		})
+	}
+}
+
+// Tests that `ChallengeFor` returns the challenge actually sent to a failing request.
+func TestChallengeFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(auth *BasicAuth)
+		header    string
+		expected  string
+	}{
+		{
+			name:      "test_static_realm",
+			configure: func(auth *BasicAuth) {},
+			expected:  `Basic realm="Test", charset="UTF-8"`,
+		},
+		{
+			name:      "test_invalid_credentials",
+			configure: func(auth *BasicAuth) {},
+			header:    "Basic Z2VyeXNhbnRvc286d3Jvbmc=",
+			expected:  `Basic realm="Test", charset="UTF-8"`,
+		},
+		{
+			name:      "test_params",
+			configure: func(auth *BasicAuth) { auth.ChallengeParams = map[string]string{"scope": "admin"} },
+			expected:  `Basic realm="Test", charset="UTF-8", scope="admin"`,
+		},
+		{
+			name:      "test_latin1",
+			configure: func(auth *BasicAuth) { auth.Charset = "ISO-8859-1" },
+			expected:  `Basic realm="Test"`,
+		},
+		{
+			name:      "test_proxy_mode",
+			configure: func(auth *BasicAuth) { auth.ProxyMode = true },
+			expected:  `Basic realm="Test", charset="UTF-8"`,
+		},
+		{
+			name:      "test_no_realm",
+			configure: func(auth *BasicAuth) { auth.Realm = "" },
+			expected:  "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Test", map[string]string{"gerysantoso": "gerysantoso"})
+			tc.configure(auth)
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			if tc.header != "" {
+				r.Header.Set("Authorization", tc.header)
+			}
+
+			if challenge := auth.ChallengeFor(r); challenge != tc.expected {
+				t.Errorf("Expected and actual challenges are different! Expected: %v. Got: %v.", tc.expected, challenge)
+			}
+
+			handler(w, r)
+
+			sent := w.Header().Get("WWW-Authenticate")
+			if auth.ProxyMode {
+				sent = w.Header().Get("Proxy-Authenticate")
+			}
+
+			if sent != tc.expected {
+				t.Errorf("Expected and actual sent challenges are different! Expected: %v. Got: %v.", tc.expected, sent)
+			}
+		})
+	}
+}
+
+// Tests the extra auth-params appended to the challenge.
+func TestChallengeParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   map[string]string
+		expected string
+	}{
+		{
+			name:     "test_no_params",
+			params:   nil,
+			expected: `Basic realm="Test", charset="UTF-8"`,
+		},
+		{
+			name:     "test_single_param",
+			params:   map[string]string{"scope": "admin"},
+			expected: `Basic realm="Test", charset="UTF-8", scope="admin"`,
+		},
+		{
+			name:     "test_sorted_params",
+			params:   map[string]string{"scope": "admin", "audience": "internal"},
+			expected: `Basic realm="Test", charset="UTF-8", audience="internal", scope="admin"`,
+		},
+		{
+			name:     "test_escaped_values",
+			params:   map[string]string{"scope": `ad"min\`},
+			expected: `Basic realm="Test", charset="UTF-8", scope="ad\"min\\"`,
+		},
+		{
+			name:     "test_control_characters_removed",
+			params:   map[string]string{"scope": "admin\r\nX-Injected: 1"},
+			expected: `Basic realm="Test", charset="UTF-8", scope="adminX-Injected: 1"`,
+		},
+		{
+			name:     "test_invalid_and_reserved_names_skipped",
+			params:   map[string]string{"sco pe": "admin", "a=b": "c", "Realm": "Other", "charset": "ISO-8859-1"},
+			expected: `Basic realm="Test", charset="UTF-8"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Test", nil)
+			auth.ChallengeParams = tc.params
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+
+			handler(w, r)
+
+			if header := w.Header().Get("WWW-Authenticate"); header != tc.expected {
+				t.Errorf("Expected and actual challenges are different! Expected: %v. Got: %v.", tc.expected, header)
+			}
+		})
+	}
+}
+
+// Tests the `Link` header pointing clients to the token endpoint on challenges.
+func TestTokenEndpoint(t *testing.T) {
+	tests := []struct {
+		name         string
+		endpoint     string
+		credentials  bool
+		expectedLink string
+	}{
+		{
+			name:         "test_relative_endpoint",
+			endpoint:     "/token",
+			expectedLink: `</token>; rel="token-endpoint"`,
+		},
+		{
+			name:         "test_absolute_endpoint",
+			endpoint:     "https://auth.example.com/oauth/token?grant_type=client_credentials",
+			expectedLink: `<https://auth.example.com/oauth/token?grant_type=client_credentials>; rel="token-endpoint"`,
+		},
+		{
+			name:         "test_escaped_endpoint",
+			endpoint:     `/token?a=<b>"c d"`,
+			expectedLink: `</token?a=%3Cb%3E%22c%20d%22>; rel="token-endpoint"`,
+		},
+		{
+			name:         "test_invalid_endpoint",
+			endpoint:     "http://[::1",
+			expectedLink: "",
+		},
+		{
+			name:         "test_no_endpoint",
+			endpoint:     "",
+			expectedLink: "",
+		},
+		{
+			name:         "test_not_sent_on_success",
+			endpoint:     "/token",
+			credentials:  true,
+			expectedLink: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Test", map[string]string{"gerysantoso": "gerysantoso"})
+			auth.TokenEndpoint = tc.endpoint
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			if tc.credentials {
+				r.SetBasicAuth("gerysantoso", "gerysantoso")
+			}
+
+			handler(w, r)
+
+			if !tc.credentials && w.Code != http.StatusUnauthorized {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusUnauthorized, w.Code)
+			}
+
+			if link := w.Header().Get("Link"); link != tc.expectedLink {
+				t.Errorf("Expected and actual links are different! Expected: %v. Got: %v.", tc.expectedLink, link)
+			}
+		})
+	}
+}
+
+// Tests that the first request of each client is challenged, even with valid credentials.
+func TestChallengeFirstWindow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Kiosk", map[string]string{"gerysantoso": "gerysantoso"})
+	auth.ChallengeFirstWindow = time.Minute
+	auth.clock = func() time.Time { return now }
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	steps := []struct {
+		name           string
+		ip             string
+		credentials    bool
+		elapsed        time.Duration
+		expectedStatus int
+	}{
+		{name: "test_first_request_challenged", ip: "192.0.2.1", credentials: true, elapsed: 0, expectedStatus: http.StatusUnauthorized},
+		{name: "test_second_request_accepted", ip: "192.0.2.1", credentials: true, elapsed: time.Second, expectedStatus: http.StatusOK},
+		{name: "test_other_client_challenged", ip: "192.0.2.2", credentials: true, elapsed: 0, expectedStatus: http.StatusUnauthorized},
+		{name: "test_challenged_again_after_window", ip: "192.0.2.1", credentials: true, elapsed: 2 * time.Minute, expectedStatus: http.StatusUnauthorized},
+		{name: "test_missing_credentials_counts_as_challenge", ip: "192.0.2.3", credentials: false, elapsed: 0, expectedStatus: http.StatusUnauthorized},
+		{name: "test_accepted_after_missing_credentials", ip: "192.0.2.3", credentials: true, elapsed: time.Second, expectedStatus: http.StatusOK},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			now = now.Add(step.elapsed)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = step.ip + ":1234"
+			w := httptest.NewRecorder()
+			if step.credentials {
+				r.SetBasicAuth("gerysantoso", "gerysantoso")
+			}
+
+			handler(w, r)
+
+			if step.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", step.expectedStatus, w.Code)
+			}
+
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected the challenge to be sent.")
+			}
+		})
+	}
+}
+
+// Tests that the markers of `ChallengeFirstWindow` stay bounded, even if none of them has expired yet, such as
+// when an attacker rotates addresses.
+func TestChallengeFirstBounded(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.ChallengeFirstWindow = time.Hour
+	auth.clock = func() time.Time { return now }
+
+	auth.challenged = make(map[string]time.Time, maxChallengedClients)
+	for i := 0; i < maxChallengedClients; i++ {
+		auth.challenged[fmt.Sprintf("2001:db8::%x", i)] = now
+	}
+
+	if !auth.challengeFirst("192.0.2.1") {
+		t.Error("Expected the new client to be challenged.")
+	}
+
+	if tracked := len(auth.challenged); tracked >= maxChallengedClients {
+		t.Errorf("Expected fewer than %v tracked clients. Got: %v.", maxChallengedClients, tracked)
+	}
+
+	if _, ok := auth.challenged["192.0.2.1"]; !ok {
+		t.Error("Expected the new client to be tracked.")
+	}
+
+	// Room has been made for many clients at once, so the next ones do not trigger an eviction.
+	tracked := len(auth.challenged)
+	auth.challengeFirst("192.0.2.2")
+	if len(auth.challenged) != tracked+1 {
+		t.Errorf("Expected and actual numbers of tracked clients are different! Expected: %v. Got: %v.", tracked+1, len(auth.challenged))
+	}
+}
@@ -0,0 +1,26 @@
+package basic
+
+import "strings"
+
+// isLatin1 checks whether a charset is ISO-8859-1, under its preferred MIME name or one of its common aliases.
+func isLatin1(charset string) bool {
+	for _, name := range []string{"ISO-8859-1", "ISO_8859-1", "latin1"} {
+		if strings.EqualFold(charset, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodeLatin1 converts a string of ISO-8859-1 bytes to UTF-8. Every byte is the code point of the same value, so
+// no conversion table is needed.
+func decodeLatin1(value string) string {
+	var sb strings.Builder
+	sb.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		sb.WriteRune(rune(value[i]))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,81 @@
+package basic
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests that credentials sent in ISO-8859-1 by legacy clients match UTF-8 users if `Charset` is ISO-8859-1.
+func TestCharsetLatin1(t *testing.T) {
+	latin1 := "Basic " + base64.StdEncoding.EncodeToString([]byte("g\xe9ry:p\xe4ss"))
+	utf8 := "Basic " + base64.StdEncoding.EncodeToString([]byte("géry:päss"))
+
+	tests := []struct {
+		name              string
+		charset           string
+		header            string
+		expectedStatus    int
+		expectedChallenge string
+	}{
+		{
+			name:              "test_latin1_credentials",
+			charset:           "ISO-8859-1",
+			header:            latin1,
+			expectedStatus:    http.StatusOK,
+			expectedChallenge: "",
+		},
+		{
+			name:              "test_latin1_alias",
+			charset:           "latin1",
+			header:            latin1,
+			expectedStatus:    http.StatusOK,
+			expectedChallenge: "",
+		},
+		{
+			name:              "test_latin1_challenge_without_charset",
+			charset:           "ISO-8859-1",
+			header:            "",
+			expectedStatus:    http.StatusUnauthorized,
+			expectedChallenge: `Basic realm="Legacy"`,
+		},
+		{
+			name:              "test_latin1_credentials_with_utf8",
+			charset:           "UTF-8",
+			header:            latin1,
+			expectedStatus:    http.StatusUnauthorized,
+			expectedChallenge: `Basic realm="Legacy", charset="UTF-8"`,
+		},
+		{
+			name:              "test_utf8_credentials_with_utf8",
+			charset:           "UTF-8",
+			header:            utf8,
+			expectedStatus:    http.StatusOK,
+			expectedChallenge: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, tc.charset, nil, nil, "Legacy", map[string]string{"géry": "päss"})
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			if tc.header != "" {
+				r.Header.Set("Authorization", tc.header)
+			}
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if challenge := w.Header().Get("WWW-Authenticate"); challenge != tc.expectedChallenge {
+				t.Errorf("Expected and actual challenges are different! Expected: %v. Got: %v.", tc.expectedChallenge, challenge)
+			}
+		})
+	}
+}
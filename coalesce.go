@@ -0,0 +1,91 @@
+package basic
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+)
+
+// processKey is a random key generated once per process. It is used to derive keys from credentials, so the
+// derived keys are neither reversible nor correlatable across processes.
+var processKey = newProcessKey()
+
+// newProcessKey generates a random 32-byte key.
+func newProcessKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("basic: failed to generate a random process key: " + err.Error())
+	}
+
+	return key
+}
+
+// credentialsKey derives a key from a pair of credentials with HMAC-SHA256, so the plaintext credentials are
+// never used as a map key. The username is prefixed with its length, as both fields may contain any byte, so no
+// other pair of credentials is encoded the same way.
+func credentialsKey(username, password string) string {
+	length := make([]byte, binary.MaxVarintLen64)
+	mac := hmac.New(sha256.New, processKey)
+	mac.Write(length[:binary.PutUvarint(length, uint64(len(username)))])
+	mac.Write([]byte(username))
+	mac.Write([]byte(password))
+
+	return string(mac.Sum(nil))
+}
+
+// flight is an in-flight authenticator call, shared by all concurrent verifications of the same credentials. Its
+// result is set before `done` is closed.
+type flight struct {
+	done chan struct{}
+	ok   bool
+	err  error
+	dups int
+}
+
+// flightGroup coalesces concurrent authenticator calls with identical credentials into a single call. This is a
+// minimal version of `golang.org/x/sync/singleflight`, kept in-house so the core package only imports the standard
+// library.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// do calls the authenticator for a key, unless a call for the same key is already in flight. In that case, it
+// waits for the call to finish and shares its result, or gives up with the error of the context once it is done, so
+// a waiting request is not held by a slow call of another one. A panicking authenticator fails all waiting calls.
+func (g *flightGroup) do(ctx context.Context, key string, authenticate func() (bool, error)) (bool, error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+
+	if f, ok := g.flights[key]; ok {
+		f.dups++
+		g.mu.Unlock()
+
+		select {
+		case <-f.done:
+			return f.ok, f.err
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+
+	f.ok, f.err = authenticate()
+
+	return f.ok, f.err
+}
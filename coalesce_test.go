@@ -0,0 +1,169 @@
+package basic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForDuplicates blocks until the given number of calls are waiting for an in-flight call.
+func waitForDuplicates(t *testing.T, g *flightGroup, dups int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		waiting := 0
+		for _, f := range g.flights {
+			waiting += f.dups
+		}
+		g.mu.Unlock()
+
+		if waiting == dups {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("Timed out waiting for %v duplicate calls.", dups)
+}
+
+// Tests that concurrent identical verifications share a single authenticator call.
+func TestCoalesceAuthentications(t *testing.T) {
+	tests := []struct {
+		name           string
+		password       string
+		expectedStatus int
+	}{
+		{
+			name:           "test_coalesced_success",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_coalesced_failure",
+			password:       "wrong_password",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			const requests = 10
+			var calls int32
+			release := make(chan struct{})
+
+			auth := NewDefaultBasicAuth(nil)
+			auth.CoalesceAuthentications = true
+			auth.Authenticator = func(username, password string) bool {
+				atomic.AddInt32(&calls, 1)
+				<-release
+
+				return username == "gerysantoso" && password == "gerysantoso"
+			}
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+			var wg sync.WaitGroup
+			codes := make([]int, requests)
+			for i := 0; i < requests; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					r := httptest.NewRequest(http.MethodGet, "/", nil)
+					w := httptest.NewRecorder()
+					r.SetBasicAuth("gerysantoso", tc.password)
+
+					handler(w, r)
+					codes[i] = w.Code
+				}(i)
+			}
+
+			waitForDuplicates(t, &auth.flights, requests-1)
+			close(release)
+			wg.Wait()
+
+			if calls := atomic.LoadInt32(&calls); calls != 1 {
+				t.Errorf("Expected and actual number of authenticator calls are different! Expected: %v. Got: %v.", 1, calls)
+			}
+
+			for _, code := range codes {
+				if code != tc.expectedStatus {
+					t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, code)
+				}
+			}
+		})
+	}
+}
+
+// Tests that a coalesced request stops waiting for the call of another one once its own context is done.
+func TestCoalesceAuthenticationsCanceled(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	auth := NewDefaultBasicAuth(nil)
+	auth.CoalesceAuthentications = true
+	auth.Authenticator = func(username, password string) bool {
+		close(started)
+		<-release
+
+		return true
+	}
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	// The first request holds the call until the test is over.
+	go func() {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.SetBasicAuth("gerysantoso", "gerysantoso")
+		handler(httptest.NewRecorder(), r)
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan int)
+	go func() {
+		r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		r.SetBasicAuth("gerysantoso", "gerysantoso")
+		handler(w, r)
+		done <- w.Code
+	}()
+
+	waitForDuplicates(t, &auth.flights, 1)
+	cancel()
+
+	select {
+	case code := <-done:
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusServiceUnavailable, code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the canceled request to stop waiting for the in-flight call.")
+	}
+}
+
+// Tests that the keys of the coalesced calls never contain the plaintext credentials.
+func TestCredentialsKey(t *testing.T) {
+	key := credentialsKey("gerysantoso", "a_password")
+	if strings.Contains(key, "gerysantoso") || strings.Contains(key, "a_password") {
+		t.Error("Expected the key to not contain the plaintext credentials.")
+	}
+
+	if key != credentialsKey("gerysantoso", "a_password") {
+		t.Error("Expected identical credentials to produce identical keys.")
+	}
+
+	if key == credentialsKey("gerysantosoa", "_password") {
+		t.Error("Expected the boundary between the username and the password to be part of the key.")
+	}
+
+	if credentialsKey("a\x00b", "c") == credentialsKey("a", "b\x00c") {
+		t.Error("Expected credentials containing NUL bytes not to collide.")
+	}
+}
@@ -0,0 +1,70 @@
+package basic
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"hash"
+	"sync"
+)
+
+// macs pools HMAC-SHA256 hashers keyed with `processKey`, as setting up the key is a large part of the cost of a MAC.
+var macs = sync.Pool{
+	New: func() interface{} {
+		return hmac.New(sha256.New, processKey)
+	},
+}
+
+// hashers pools SHA-256 hashers for `digest`, each with the buffer used to copy the input to, so hashing a long
+// input does not allocate.
+var hashers = sync.Pool{
+	New: func() interface{} {
+		return &hasher{hash: sha256.New()}
+	},
+}
+
+// hasher is a SHA-256 hasher pooled by `hashers`.
+type hasher struct {
+	hash   hash.Hash
+	buffer [sha256.BlockSize]byte
+	sum    [sha256.Size]byte
+}
+
+// digest returns the SHA-256 digest of an input. The input is streamed through the buffer of a pooled hasher, as
+// converting it to a byte slice would copy it to the heap, so hashing never allocates. The buffer is owned by this
+// package, so it is wiped afterwards.
+func digest(input string) [sha256.Size]byte {
+	h := hashers.Get().(*hasher)
+	defer hashers.Put(h)
+
+	h.hash.Reset()
+	for len(input) > 0 {
+		n := copy(h.buffer[:], input)
+		h.hash.Write(h.buffer[:n])
+		input = input[n:]
+	}
+	zero(h.buffer[:])
+	h.hash.Sum(h.sum[:0])
+
+	return h.sum
+}
+
+// CompareInputsHMAC is a variant of `CompareInputs`, which compares the HMAC-SHA256 of both inputs under a random
+// per-process key in constant time. Like `CompareInputs`, it does not leak the lengths of the inputs. As the key is
+// secret, collisions cannot be searched for offline, so equality does not rely on the collision resistance of a bare
+// digest. It is not faster than `CompareInputs`, see `BenchmarkCompareInputs` and `BenchmarkCompareInputsHMAC`.
+func CompareInputsHMAC(input, expected string) bool {
+	mac := macs.Get().(hash.Hash)
+	defer macs.Put(mac)
+
+	var inputMAC, expectedMAC [sha256.Size]byte
+	mac.Reset()
+	mac.Write([]byte(input))
+	mac.Sum(inputMAC[:0])
+
+	mac.Reset()
+	mac.Write([]byte(expected))
+	mac.Sum(expectedMAC[:0])
+
+	return subtle.ConstantTimeCompare(inputMAC[:], expectedMAC[:]) == 1
+}
@@ -0,0 +1,49 @@
+package basic
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+// Tests the constant-time comparisons of inputs.
+func TestCompareInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		equal    bool
+	}{
+		{name: "test_equal", input: "gerysantoso", expected: "gerysantoso", equal: true},
+		{name: "test_different", input: "gerysantoso", expected: "nicholasdwiarto", equal: false},
+		{name: "test_prefix", input: "gery", expected: "gerysantoso", equal: false},
+		{name: "test_case", input: "GerySantoso", expected: "gerysantoso", equal: false},
+		{name: "test_empty", input: "", expected: "", equal: true},
+		{name: "test_empty_input", input: "", expected: "gerysantoso", equal: false},
+		{name: "test_long_equal", input: strings.Repeat("gerysantoso", 20), expected: strings.Repeat("gerysantoso", 20), equal: true},
+		{name: "test_long_different", input: strings.Repeat("gerysantoso", 20) + "!", expected: strings.Repeat("gerysantoso", 20), equal: false},
+		{name: "test_block_boundary", input: strings.Repeat("a", 64), expected: strings.Repeat("a", 65), equal: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if equal := CompareInputs(tc.input, tc.expected); equal != tc.equal {
+				t.Errorf("Expected and actual results of CompareInputs are different! Expected: %v. Got: %v.", tc.equal, equal)
+			}
+
+			if equal := CompareInputsHMAC(tc.input, tc.expected); equal != tc.equal {
+				t.Errorf("Expected and actual results of CompareInputsHMAC are different! Expected: %v. Got: %v.", tc.equal, equal)
+			}
+		})
+	}
+}
+
+// Tests that digests are the same whether the input is hashed on the stack or streamed through a pooled hasher.
+func TestDigest(t *testing.T) {
+	for _, length := range []int{0, 1, 63, 64, 65, 128, 1000} {
+		input := strings.Repeat("g", length)
+		if expected, actual := sha256.Sum256([]byte(input)), digest(input); expected != actual {
+			t.Errorf("Expected and actual digests of %d bytes are different! Expected: %x. Got: %x.", length, expected, actual)
+		}
+	}
+}
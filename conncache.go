@@ -0,0 +1,124 @@
+package basic
+
+import (
+	"context"
+	"crypto/hmac"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// connCacheContextKey is the key of the cache of a connection stored in a context.
+type connCacheContextKey struct{}
+
+// connCache remembers the credentials verified on a single connection, with at most one entry per `BasicAuth`
+// instance, so a connection cannot inherit the verification of another realm.
+type connCache struct {
+	mu      sync.Mutex
+	entries map[*BasicAuth]connCacheEntry
+}
+
+// connCacheEntry is the key of the last credentials verified on a connection, with the generation of the
+// authenticator that verified them, the version of the credentials of the user, and the time of the verification.
+type connCacheEntry struct {
+	key        string
+	generation uint32
+	version    string
+	verifiedAt time.Time
+}
+
+// ConnContext prepares a connection for `ConnectionCache`. It has to be set as the `ConnContext` of the
+// `http.Server`, so every connection gets its own cache, which is dropped along with the connection.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connCacheContextKey{}, &connCache{})
+}
+
+// connectionCache returns the cache of the connection of a request if it can be used, or `nil` otherwise. It is
+// not used for users with a second factor, as codes have to be verified every time to reject replays, nor for
+// per-request authenticators, whose verdict may depend on more than the credentials.
+func (a *BasicAuth) connectionCache(r *http.Request, code string) *connCache {
+	if !a.ConnectionCache || code != "" || a.AuthenticatorWithRequest != nil {
+		return nil
+	}
+
+	if _, ok := contextAuthenticator(r.Context()); ok {
+		return nil
+	}
+
+	cache, _ := r.Context().Value(connCacheContextKey{}).(*connCache)
+
+	return cache
+}
+
+// entry builds the cache entry of the credentials of a request. It is built before the credentials are verified, so
+// that credentials changed during the verification are not remembered with the generation and version of the new ones.
+func (c *connCache) entry(a *BasicAuth, username, password string) connCacheEntry {
+	if c == nil {
+		return connCacheEntry{}
+	}
+
+	return connCacheEntry{
+		key:        credentialsKey(username, password),
+		generation: atomic.LoadUint32(&a.generation),
+		version:    a.credentialVersion(username),
+		verifiedAt: a.now(),
+	}
+}
+
+// verified checks whether the same credentials have already been verified on the connection by the same
+// authenticator, with the same version of the credentials of the user, and no longer than `ConnectionCacheMaxAge`
+// ago. Credentials are compared through their keyed hashes in constant time.
+func (c *connCache) verified(a *BasicAuth, current connCacheEntry) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[a]
+	c.mu.Unlock()
+
+	return ok &&
+		entry.generation == current.generation &&
+		entry.version == current.version &&
+		(a.ConnectionCacheMaxAge <= 0 || current.verifiedAt.Sub(entry.verifiedAt) < a.ConnectionCacheMaxAge) &&
+		hmac.Equal([]byte(entry.key), []byte(current.key))
+}
+
+// remember stores the credentials verified on the connection, replacing any previous ones.
+func (c *connCache) remember(a *BasicAuth, entry connCacheEntry) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[*BasicAuth]connCacheEntry)
+	}
+
+	c.entries[a] = entry
+}
+
+// credentialVersion returns the version of the credentials of a user from `CredentialVersion`, if set.
+func (a *BasicAuth) credentialVersion(username string) string {
+	if a.CredentialVersion == nil {
+		return ""
+	}
+
+	return a.CredentialVersion(username)
+}
+
+// forget drops the credentials verified on the connection, after other credentials failed on it.
+func (c *connCache) forget(a *BasicAuth) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, a)
+}
@@ -0,0 +1,143 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Tests that `ConnectionCache` only skips verifying the very same credentials again on the same connection.
+func TestConnectionCache(t *testing.T) {
+	calls := 0
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso", "nicholasdwiarto": "nicholasdwiarto"})
+	auth.ConnectionCache = true
+	auth.Authenticator = func(username, password string) bool {
+		calls++
+		return auth.verifyUser(username, password)
+	}
+
+	server := httptest.NewUnstartedServer(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+	server.Config.ConnContext = ConnContext
+	server.Start()
+	defer server.Close()
+
+	steps := []struct {
+		name           string
+		username       string
+		password       string
+		newConnection  bool
+		swap           bool
+		expectedStatus int
+		expectedCalls  int
+	}{
+		{name: "test_first_request", username: "gerysantoso", password: "gerysantoso", expectedStatus: http.StatusOK, expectedCalls: 1},
+		{name: "test_same_credentials", username: "gerysantoso", password: "gerysantoso", expectedStatus: http.StatusOK, expectedCalls: 1},
+		{name: "test_changed_password", username: "gerysantoso", password: "wrong", expectedStatus: http.StatusUnauthorized, expectedCalls: 2},
+		{name: "test_previous_credentials_verified_again", username: "gerysantoso", password: "gerysantoso", expectedStatus: http.StatusOK, expectedCalls: 3},
+		{name: "test_changed_user", username: "nicholasdwiarto", password: "nicholasdwiarto", expectedStatus: http.StatusOK, expectedCalls: 4},
+		{name: "test_other_connection", username: "nicholasdwiarto", password: "nicholasdwiarto", newConnection: true, expectedStatus: http.StatusOK, expectedCalls: 5},
+		{name: "test_swapped_authenticator", username: "nicholasdwiarto", password: "nicholasdwiarto", swap: true, expectedStatus: http.StatusUnauthorized, expectedCalls: 5},
+	}
+
+	client := server.Client()
+	for _, step := range steps {
+		if step.newConnection {
+			client.CloseIdleConnections()
+		}
+
+		if step.swap {
+			auth.SetAuthenticator(func(username, password string) bool { return false })
+		}
+
+		r, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.SetBasicAuth(step.username, step.password)
+
+		res, err := client.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != step.expectedStatus {
+			t.Errorf("%s: Expected and actual status code values are different! Expected: %v. Got: %v.", step.name, step.expectedStatus, res.StatusCode)
+		}
+
+		if calls != step.expectedCalls {
+			t.Errorf("%s: Expected and actual authenticator calls are different! Expected: %v. Got: %v.", step.name, step.expectedCalls, calls)
+		}
+	}
+}
+
+// Tests that credentials remembered by `ConnectionCache` are verified again after a rotation, a change of their
+// version, or once they are older than `ConnectionCacheMaxAge`, even on the same connection.
+func TestConnectionCacheInvalidation(t *testing.T) {
+	calls := 0
+	now := time.Now()
+	version := "1"
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.ConnectionCache = true
+	auth.ConnectionCacheMaxAge = time.Minute
+	auth.CredentialVersion = func(username string) string { return version }
+	auth.clock = func() time.Time { return now }
+	auth.Authenticator = func(username, password string) bool {
+		calls++
+		return auth.verifyUser(username, password)
+	}
+
+	server := httptest.NewUnstartedServer(auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+	server.Config.ConnContext = ConnContext
+	server.Start()
+	defer server.Close()
+
+	steps := []struct {
+		name           string
+		password       string
+		rotate         string
+		version        string
+		advance        time.Duration
+		expectedStatus int
+		expectedCalls  int
+	}{
+		{name: "test_first_request", password: "gerysantoso", version: "1", expectedStatus: http.StatusOK, expectedCalls: 1},
+		{name: "test_cached", password: "gerysantoso", version: "1", advance: 30 * time.Second, expectedStatus: http.StatusOK, expectedCalls: 1},
+		{name: "test_rotated_password", password: "gerysantoso", rotate: "rotated", version: "1", expectedStatus: http.StatusUnauthorized, expectedCalls: 2},
+		{name: "test_new_password", password: "rotated", version: "1", expectedStatus: http.StatusOK, expectedCalls: 3},
+		{name: "test_new_password_cached", password: "rotated", version: "1", expectedStatus: http.StatusOK, expectedCalls: 3},
+		{name: "test_changed_version", password: "rotated", version: "2", expectedStatus: http.StatusOK, expectedCalls: 4},
+		{name: "test_within_max_age", password: "rotated", version: "2", advance: 59 * time.Second, expectedStatus: http.StatusOK, expectedCalls: 4},
+		{name: "test_expired", password: "rotated", version: "2", advance: time.Second, expectedStatus: http.StatusOK, expectedCalls: 5},
+	}
+
+	client := server.Client()
+	for _, step := range steps {
+		if step.rotate != "" {
+			auth.AddUser("gerysantoso", step.rotate)
+		}
+		version = step.version
+		now = now.Add(step.advance)
+
+		r, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.SetBasicAuth("gerysantoso", step.password)
+
+		res, err := client.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != step.expectedStatus {
+			t.Errorf("%s: Expected and actual status code values are different! Expected: %v. Got: %v.", step.name, step.expectedStatus, res.StatusCode)
+		}
+
+		if calls != step.expectedCalls {
+			t.Errorf("%s: Expected and actual authenticator calls are different! Expected: %v. Got: %v.", step.name, step.expectedCalls, calls)
+		}
+	}
+}
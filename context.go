@@ -0,0 +1,78 @@
+package basic
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+)
+
+// authenticatorContextKey is the key of the per-request authenticator stored in a context. The type is unexported
+// to prevent collisions with keys defined in other packages.
+type authenticatorContextKey struct{}
+
+// clientIPContextKey is the key of the IP address of the authenticated client stored in a context.
+type clientIPContextKey struct{}
+
+// usernameContextKey is the key of the authenticated username stored in a context.
+type usernameContextKey struct{}
+
+// handlerNameContextKey is the key of the name of the protected handler stored in a context.
+type handlerNameContextKey struct{}
+
+// ContextWithAuthenticator returns a copy of the context carrying an authenticator function for a single request. When present,
+// it takes precedence over the `Authenticator` attribute of `BasicAuth`, which stays the fallback for all other requests.
+// This is useful for multi-tenant setups, where an earlier middleware picks the authenticator (for example, based on the
+// tenant of the request), and for testing. A `nil` authenticator is ignored.
+func ContextWithAuthenticator(ctx context.Context, authenticator func(username, password string) bool) context.Context {
+	return context.WithValue(ctx, authenticatorContextKey{}, authenticator)
+}
+
+// UsernameFromContext returns the username of the user authenticated by `Authenticate`, so protected handlers do not
+// have to parse the `Authorization` header again. It is only set on successful authentications.
+func UsernameFromContext(ctx context.Context) (string, bool) {
+	username, ok := ctx.Value(usernameContextKey{}).(string)
+
+	return username, ok
+}
+
+// HandlerNameFromContext returns the name of the handler protected by `Authenticate`, as reported by `runtime.FuncForPC`
+// (for example, `main.privateHandler`), or its type if it is not a function (for example, `*http.ServeMux` protected
+// with `Middleware`). Wrapping a handler in a closure hides it from introspection, so the name is
+// resolved once when wrapping, and exposed to the protected handler and the middlewares after it for logging or metrics.
+func HandlerNameFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(handlerNameContextKey{}).(string)
+
+	return name, ok
+}
+
+// ClientIPFromContext returns the IP address of the authenticated client, as resolved by the middleware for the audit
+// trail and `ChallengeFirstWindow`. Downstream handlers should use it instead of parsing the request again, so that
+// every decision about a client is made with the same IP address.
+func ClientIPFromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPContextKey{}).(string)
+
+	return ip, ok
+}
+
+// handlerName resolves the name of a handler function, or the type of any other handler (such as `*http.ServeMux`).
+// It returns an empty string if it cannot be resolved.
+func handlerName(handler interface{}) string {
+	value := reflect.ValueOf(handler)
+	if !value.IsValid() {
+		return ""
+	}
+
+	if value.Kind() != reflect.Func {
+		return value.Type().String()
+	}
+
+	if value.IsNil() {
+		return ""
+	}
+
+	if fn := runtime.FuncForPC(value.Pointer()); fn != nil {
+		return fn.Name()
+	}
+
+	return ""
+}
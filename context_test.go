@@ -0,0 +1,106 @@
+package basic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// protectedHandler is a named handler, so its name can be resolved from the context.
+func protectedHandler(w http.ResponseWriter, r *http.Request) {
+	name, _ := HandlerNameFromContext(r.Context())
+	w.Header().Set("X-Handler-Name", name)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Tests that the name of the protected handler is retrievable from the context.
+func TestHandlerNameFromContext(t *testing.T) {
+	basicAuth := NewDefaultBasicAuth(map[string]string{"admin": "admin123"})
+	handler := basicAuth.Authenticate(protectedHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("admin", "admin123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusOK, rec.Code)
+	}
+
+	expectedName := "github.com/lauslim12/basic.protectedHandler"
+	if name := rec.Header().Get("X-Handler-Name"); name != expectedName {
+		t.Errorf("Expected and actual handler names are different! Expected: %v. Got: %v.", expectedName, name)
+	}
+
+	if _, ok := HandlerNameFromContext(context.Background()); ok {
+		t.Errorf("Expected no handler name in a context that did not pass through the middleware.")
+	}
+}
+
+// Tests that the client IP in the context matches the one used by the middleware.
+func TestClientIPFromContext(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		expectedIP string
+	}{
+		{
+			name:       "test_ipv4",
+			remoteAddr: "203.0.113.7:52100",
+			expectedIP: "203.0.113.7",
+		},
+		{
+			name:       "test_ipv6",
+			remoteAddr: "[2001:db8::1]:52100",
+			expectedIP: "2001:db8::1",
+		},
+		{
+			name:       "test_without_port",
+			remoteAddr: "203.0.113.7",
+			expectedIP: "203.0.113.7",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var ip string
+			var ok bool
+			basicAuth := NewDefaultBasicAuth(map[string]string{"admin": "admin123"})
+			handler := basicAuth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+				ip, ok = ClientIPFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			req.SetBasicAuth("admin", "admin123")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if !ok || ip != tc.expectedIP || ip != clientIP(req) {
+				t.Errorf("Expected and actual client IPs are different! Expected: %v. Got: %v.", tc.expectedIP, ip)
+			}
+		})
+	}
+}
+
+// Tests that the authenticated username is retrievable from the context of the protected handler.
+func TestUsernameFromContext(t *testing.T) {
+	var username string
+	var ok bool
+	basicAuth := NewDefaultBasicAuth(map[string]string{"admin": "admin123"})
+	handler := basicAuth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		username, ok = UsernameFromContext(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("admin", "admin123")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !ok || username != "admin" {
+		t.Errorf("Expected and actual usernames are different! Expected: %v. Got: %v.", "admin", username)
+	}
+
+	if _, ok := UsernameFromContext(context.Background()); ok {
+		t.Errorf("Expected no username in a context that did not pass through the middleware.")
+	}
+}
@@ -0,0 +1,55 @@
+package basic
+
+import "crypto/subtle"
+
+// DenyCredential revokes a pair of credentials instantly, even if it is still valid in the store, such as when a
+// credential has leaked and its rotation is pending. Only a keyed hash of the pair is kept in memory (see
+// `credentialsKey`), so the plaintext password is not retained, and other passwords of the same user still work.
+// The denylist lives in memory, so it does not survive restarts. It is safe to call concurrently with requests.
+func (a *BasicAuth) DenyCredential(username, password string) {
+	key := credentialsKey(username, password)
+
+	a.deniedMu.Lock()
+	defer a.deniedMu.Unlock()
+
+	if a.denied == nil {
+		a.denied = make(map[string]struct{})
+	}
+
+	a.denied[key] = struct{}{}
+}
+
+// isDenied checks whether a pair of credentials has been revoked with `DenyCredential`. Once the denylist is in use,
+// the key is derived for every request, so denied and allowed credentials take the same time to be checked.
+func (a *BasicAuth) isDenied(username, password string) bool {
+	a.deniedMu.RLock()
+	defer a.deniedMu.RUnlock()
+
+	if len(a.denied) == 0 {
+		return false
+	}
+
+	_, denied := a.denied[credentialsKey(username, password)]
+
+	return denied
+}
+
+// isDeniedUser checks whether a username is listed in `DeniedUsers`. Every entry is compared in constant time, and
+// the comparison never stops early, so the time taken does not tell which usernames are listed, or where.
+func (a *BasicAuth) isDeniedUser(username string) bool {
+	if len(a.DeniedUsers) == 0 {
+		return false
+	}
+
+	// The username is only hashed once, instead of once per entry as `CompareInputs` would.
+	usernameHash := digest(username)
+	denied := false
+	for _, deniedUser := range a.DeniedUsers {
+		deniedHash := digest(deniedUser)
+		if subtle.ConstantTimeCompare(usernameHash[:], deniedHash[:]) == 1 {
+			denied = true
+		}
+	}
+
+	return denied
+}
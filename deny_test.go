@@ -0,0 +1,121 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests that denied credentials are rejected, even if they are still valid in the store.
+func TestDenyCredential(t *testing.T) {
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso", "nicholasdwiarto": "nicholasdwiarto"})
+	auth.DenyCredential("gerysantoso", "gerysantoso")
+	auth.DenyCredential("nicholasdwiarto", "leaked")
+
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		expectedStatus int
+	}{
+		{
+			name:           "test_denied_credential",
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_other_user",
+			username:       "nicholasdwiarto",
+			password:       "nicholasdwiarto",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_denied_password_of_other_user",
+			username:       "nicholasdwiarto",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests that denied users always fail, even when they are valid in `Users` or accepted by the authenticator.
+func TestDeniedUsers(t *testing.T) {
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		authenticator  func(username, password string) bool
+		caseFold       bool
+		expectedStatus int
+	}{
+		{
+			name:           "test_denied_user_in_users",
+			username:       "root",
+			password:       "root",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_denied_user_accepted_by_authenticator",
+			username:       "admin",
+			password:       "anything",
+			authenticator:  func(username, password string) bool { return true },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_denied_user_after_normalization",
+			username:       "ROOT",
+			password:       "root",
+			caseFold:       true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_allowed_user",
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_prefix_of_denied_user",
+			username:       "roo",
+			password:       "roo",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"root": "root", "roo": "roo", "gerysantoso": "gerysantoso"})
+			auth.DeniedUsers = []string{"root", "admin"}
+			auth.UsernameCaseFold = tc.caseFold
+			if tc.authenticator != nil {
+				auth.Authenticator = tc.authenticator
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package basic
+
+import (
+	"net/http"
+	"time"
+)
+
+// AuthEvent is a single authentication attempt, passed to `Logger`. It never carries the password.
+type AuthEvent struct {
+	Username     string        // Username of the attempt, or an empty string if no credentials could be parsed.
+	RemoteAddr   string        // Network address of the client, as found in `(*http.Request).RemoteAddr`.
+	Timestamp    time.Time     // Time of the decision.
+	Outcome      string        // Outcome of the attempt, as named in the audit trail, such as `success`, `invalid_scheme`, or `invalid_credentials`.
+	Realm        string        // Realm protecting the route.
+	PasswordHash string        // Keyed hash of the password of a failed attempt if `AuditPasswordHashes` is set, or an empty string.
+	RequestID    string        // Correlation ID of the request if `RequestIDs` is set, as in the audit trail, or an empty string.
+	Slow         bool          // Whether the attempt took longer than `SlowAuthThreshold`, and should be logged as a warning.
+	Elapsed      time.Duration // Time taken by the attempt if `Slow` is set, or zero.
+}
+
+// log passes an authentication attempt to `Logger` if set. Nothing is allocated if it is not set.
+func (a *BasicAuth) log(r *http.Request, decided time.Time, username, passwordHash string, res result, elapsed time.Duration) {
+	if a.Logger == nil {
+		return
+	}
+
+	slow := a.isSlow(elapsed)
+	if !slow {
+		elapsed = 0
+	}
+
+	requestID, _ := RequestIDFromContext(r.Context())
+	a.Logger(AuthEvent{
+		Username:     username,
+		RemoteAddr:   r.RemoteAddr,
+		Timestamp:    decided,
+		Outcome:      res.String(),
+		Realm:        a.realmFor(r),
+		PasswordHash: passwordHash,
+		RequestID:    requestID,
+		Slow:         slow,
+		Elapsed:      elapsed,
+	})
+}
@@ -0,0 +1,121 @@
+package basic
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Tests that every authentication attempt is passed to `Logger`, without the password.
+func TestLogger(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name             string
+		header           string
+		expectedUsername string
+		expectedOutcome  string
+	}{
+		{
+			name:             "test_success",
+			header:           "Basic Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28=",
+			expectedUsername: "gerysantoso",
+			expectedOutcome:  "success",
+		},
+		{
+			name:             "test_invalid_scheme",
+			header:           "Bearer token",
+			expectedUsername: "",
+			expectedOutcome:  "invalid_scheme",
+		},
+		{
+			name:             "test_invalid_credentials",
+			header:           "Basic Z2VyeXNhbnRvc286c2VjcmV0X3Bhc3N3b3Jk",
+			expectedUsername: "gerysantoso",
+			expectedOutcome:  "invalid_credentials",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var events []AuthEvent
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Private", map[string]string{"gerysantoso": "gerysantoso"})
+			auth.clock = func() time.Time { return now }
+			auth.Logger = func(event AuthEvent) { events = append(events, event) }
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = "192.0.2.1:1234"
+			r.Header.Set("Authorization", tc.header)
+			w := httptest.NewRecorder()
+
+			handler(w, r)
+
+			expected := AuthEvent{
+				Username:   tc.expectedUsername,
+				RemoteAddr: "192.0.2.1:1234",
+				Timestamp:  now,
+				Outcome:    tc.expectedOutcome,
+				Realm:      "Private",
+			}
+			if len(events) != 1 || events[0] != expected {
+				t.Fatalf("Expected and actual events are different! Expected: %+v. Got: %+v.", expected, events)
+			}
+		})
+	}
+}
+
+// Tests that authentications slower than `SlowAuthThreshold` are reported as warnings, with their duration.
+func TestSlowAuthThreshold(t *testing.T) {
+	tests := []struct {
+		name         string
+		threshold    time.Duration
+		delay        time.Duration
+		expectedSlow bool
+	}{
+		{name: "test_slow", threshold: 10 * time.Millisecond, delay: 50 * time.Millisecond, expectedSlow: true},
+		{name: "test_fast", threshold: time.Second, delay: 0, expectedSlow: false},
+		{name: "test_disabled", threshold: 0, delay: 50 * time.Millisecond, expectedSlow: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			var events []AuthEvent
+			auth := NewDefaultBasicAuth(nil)
+			auth.SlowAuthThreshold = tc.threshold
+			auth.AuditWriter = buf
+			auth.Logger = func(event AuthEvent) { events = append(events, event) }
+			auth.Authenticator = func(username, password string) bool {
+				time.Sleep(tc.delay)
+				return username == "gerysantoso" && password == "gerysantoso"
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.SetBasicAuth("gerysantoso", "gerysantoso")
+			handler(httptest.NewRecorder(), r)
+			auth.FlushAudit()
+
+			if len(events) != 1 || events[0].Username != "gerysantoso" || events[0].Slow != tc.expectedSlow {
+				t.Fatalf("Expected and actual slow events are different! Expected: %v. Got: %+v.", tc.expectedSlow, events)
+			}
+
+			if tc.expectedSlow && events[0].Elapsed < tc.delay || !tc.expectedSlow && events[0].Elapsed != 0 {
+				t.Errorf("Expected and actual elapsed times are different! Expected at least: %v. Got: %v.", tc.delay, events[0].Elapsed)
+			}
+
+			record := map[string]string{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("Failed to decode the audit record: %v.", err)
+			}
+
+			if (record["level"] == "warn") != tc.expectedSlow || (record["elapsed"] != "") != tc.expectedSlow {
+				t.Errorf("Expected and actual slow audit records are different! Expected: %v. Got: %v.", tc.expectedSlow, record)
+			}
+		})
+	}
+}
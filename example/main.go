@@ -98,14 +98,19 @@ func main() {
 	users := map[string]string{"gerysantoso": "gerysantoso"}
 
 	// Create simple configuration for Basic Authentication.
-	basicAuthSimple := basic.NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Private", users)
+	basicAuthSimple := basic.New(basic.WithRealm("Private"), basic.WithUsers(users))
 
 	// Create complex configuration for Basic Authentication.
-	basicAuthComplex := basic.NewCustomBasicAuth(nil, "UTF-8", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		SendFailure(w, http.StatusUnauthorized, "Invalid authentication scheme!", "BAUTH: E0001")
-	}), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		SendFailure(w, http.StatusUnauthorized, "Invalid username and/or password!", "BAUTH: E0002")
-	}), "Secret", users)
+	basicAuthComplex := basic.New(
+		basic.WithRealm("Secret"),
+		basic.WithUsers(users),
+		basic.WithInvalidSchemeResponse(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			SendFailure(w, http.StatusUnauthorized, "Invalid authentication scheme!", "BAUTH: E0001")
+		})),
+		basic.WithInvalidCredentialsResponse(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			SendFailure(w, http.StatusUnauthorized, "Invalid username and/or password!", "BAUTH: E0002")
+		})),
+	)
 
 	// Hello endpoint!
 	http.HandleFunc("/simple", basicAuthSimple.Authenticate(simpleAuth))
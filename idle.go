@@ -0,0 +1,66 @@
+package basic
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SendIdleExpiredResponse is used to send back an invalid response if the client has been idle for longer than
+// `IdleTimeout`. The challenge carries the realm with a suffix that changes every time (such as `Private (idle
+// lv1a2b)`), as browsers cache credentials per realm, and only prompt for them again for an unknown realm.
+//
+// This is best-effort, as Basic Authentication has no logout. Browsers may still resend the cached credentials
+// without prompting, clients are told apart by their IP only, so clients behind the same NAT share their idle
+// time, and the idle times are kept in the memory of this instance, so they are lost on restart.
+func (a *BasicAuth) SendIdleExpiredResponse(w http.ResponseWriter, r *http.Request) {
+	realm := a.realmFor(r)
+	if realm != "" {
+		realm += " (idle " + strconv.FormatInt(a.now().UnixNano(), 36) + ")"
+	}
+
+	a.sendChallenge(w, r, a.InvalidCredentialsResponse, a.challengeRealm(realm))
+}
+
+// idleExpired checks whether the last successful request of a client is older than `IdleTimeout`. If it is, the
+// client is forgotten, so its next request with valid credentials is accepted again.
+func (a *BasicAuth) idleExpired(ip string) bool {
+	if a.IdleTimeout <= 0 {
+		return false
+	}
+
+	a.idleMu.Lock()
+	defer a.idleMu.Unlock()
+
+	seen, ok := a.lastSeen[ip]
+	if !ok || a.now().Sub(seen) < a.IdleTimeout {
+		return false
+	}
+
+	delete(a.lastSeen, ip)
+
+	return true
+}
+
+// touch records the time of a successful request of a client, used by `IdleTimeout`.
+func (a *BasicAuth) touch(ip string) {
+	if a.IdleTimeout <= 0 {
+		return
+	}
+
+	now := a.now()
+
+	a.idleMu.Lock()
+	defer a.idleMu.Unlock()
+
+	if a.lastSeen == nil {
+		a.lastSeen = make(map[string]time.Time)
+	}
+
+	// Keep the records bounded. Evicting the record of a client only forgets its idle time, as if it had never been
+	// seen, so it is not challenged until it is idle again.
+	if _, ok := a.lastSeen[ip]; !ok {
+		makeClientRoom(a.lastSeen, now, a.IdleTimeout)
+	}
+	a.lastSeen[ip] = now
+}
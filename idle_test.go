@@ -0,0 +1,77 @@
+package basic
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests that idle clients are challenged again with a changed realm, even with valid credentials.
+func TestIdleTimeout(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Admin", map[string]string{"gerysantoso": "gerysantoso"})
+	auth.IdleTimeout = 15 * time.Minute
+	auth.clock = func() time.Time { return now }
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	steps := []struct {
+		name           string
+		ip             string
+		elapsed        time.Duration
+		expectedStatus int
+		expectedIdle   bool
+	}{
+		{name: "test_first_request_accepted", ip: "192.0.2.1", elapsed: 0, expectedStatus: http.StatusOK},
+		{name: "test_active_client_accepted", ip: "192.0.2.1", elapsed: 10 * time.Minute, expectedStatus: http.StatusOK},
+		{name: "test_other_client_accepted", ip: "192.0.2.2", elapsed: 10 * time.Minute, expectedStatus: http.StatusOK},
+		{name: "test_idle_client_challenged", ip: "192.0.2.1", elapsed: 6 * time.Minute, expectedStatus: http.StatusUnauthorized, expectedIdle: true},
+		{name: "test_accepted_after_reauthentication", ip: "192.0.2.1", elapsed: time.Second, expectedStatus: http.StatusOK},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			now = now.Add(step.elapsed)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = step.ip + ":1234"
+			w := httptest.NewRecorder()
+			r.SetBasicAuth("gerysantoso", "gerysantoso")
+
+			handler(w, r)
+
+			if step.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", step.expectedStatus, w.Code)
+			}
+
+			header := w.Header().Get("WWW-Authenticate")
+			if step.expectedIdle && (!strings.HasPrefix(header, `Basic realm="Admin (idle `) || header == auth.challenge()) {
+				t.Errorf("Expected a challenge with a changed realm. Got: %v.", header)
+			}
+		})
+	}
+}
+
+// Tests that the records of `IdleTimeout` stay bounded, even if none of them has expired yet.
+func TestIdleTimeoutBounded(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.IdleTimeout = time.Hour
+	auth.clock = func() time.Time { return now }
+
+	auth.lastSeen = make(map[string]time.Time, maxChallengedClients)
+	for i := 0; i < maxChallengedClients; i++ {
+		auth.lastSeen[fmt.Sprintf("2001:db8::%x", i)] = now
+	}
+
+	auth.touch("192.0.2.1")
+
+	if tracked := len(auth.lastSeen); tracked >= maxChallengedClients {
+		t.Errorf("Expected fewer than %v tracked clients. Got: %v.", maxChallengedClients, tracked)
+	}
+
+	if _, ok := auth.lastSeen["192.0.2.1"]; !ok {
+		t.Error("Expected the new client to be tracked.")
+	}
+}
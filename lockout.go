@@ -0,0 +1,132 @@
+package basic
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxLockoutEntries is the number of usernames tracked by `MaxFailures` before stale entries are purged.
+const maxLockoutEntries = 10000
+
+// lockoutEntry tracks the recent failed attempts of a single username.
+type lockoutEntry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// lockoutEnabled checks whether brute-force protection is configured.
+func (a *BasicAuth) lockoutEnabled() bool {
+	return a.MaxFailures > 0 && a.LockoutDuration > 0
+}
+
+// isLockedOut checks whether a username is currently locked out after too many failed attempts.
+func (a *BasicAuth) isLockedOut(username string) bool {
+	return a.lockoutRemaining(username) > 0
+}
+
+// lockoutRemaining returns how long a username stays locked out, or zero if it is not locked out.
+func (a *BasicAuth) lockoutRemaining(username string) time.Duration {
+	if !a.lockoutEnabled() {
+		return 0
+	}
+
+	now := a.now()
+
+	a.lockoutMu.Lock()
+	defer a.lockoutMu.Unlock()
+
+	entry, ok := a.lockouts[username]
+	if !ok || !now.Before(entry.lockedUntil) {
+		return 0
+	}
+
+	return entry.lockedUntil.Sub(now)
+}
+
+// setRetryAfter sets the `Retry-After` header to the number of seconds (rounded up) until the lockout of a username
+// expires, so well-behaved clients back off. The username is the normalized one the lockouts are keyed by. Nothing
+// is set if it is not locked out.
+func (a *BasicAuth) setRetryAfter(w http.ResponseWriter, username string) {
+	if remaining := a.lockoutRemaining(username); remaining > 0 {
+		seconds := (remaining + time.Second - 1) / time.Second
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(seconds), 10))
+	}
+}
+
+// recordFailure counts a failed attempt of a username, and locks it out for `LockoutDuration` once `MaxFailures`
+// consecutive failures have been made, each within `LockoutDuration` of the previous one.
+func (a *BasicAuth) recordFailure(username string) {
+	if !a.lockoutEnabled() {
+		return
+	}
+
+	now := a.now()
+
+	a.lockoutMu.Lock()
+	defer a.lockoutMu.Unlock()
+
+	if a.lockouts == nil {
+		a.lockouts = make(map[string]lockoutEntry)
+	}
+
+	entry, ok := a.lockouts[username]
+	if !ok {
+		a.makeLockoutRoom(now)
+	}
+
+	// Start over once the previous failures (or the previous lockout) are old enough.
+	if now.Sub(entry.lastFailure) >= a.LockoutDuration && !now.Before(entry.lockedUntil) {
+		entry = lockoutEntry{}
+	}
+
+	entry.failures++
+	entry.lastFailure = now
+	if entry.failures >= a.MaxFailures {
+		entry.failures = 0
+		entry.lockedUntil = now.Add(a.LockoutDuration)
+	}
+
+	a.lockouts[username] = entry
+}
+
+// resetFailures forgets the failed attempts of a username after a successful authentication.
+func (a *BasicAuth) resetFailures(username string) {
+	if !a.lockoutEnabled() {
+		return
+	}
+
+	a.lockoutMu.Lock()
+	defer a.lockoutMu.Unlock()
+
+	delete(a.lockouts, username)
+}
+
+// makeLockoutRoom keeps the tracked usernames bounded. Once there are too many, stale entries are purged, and if
+// there are still too many, random entries are evicted until a tenth of the room is free again, so the purge does
+// not run on every new username under attack. Must be called with `lockoutMu` held.
+func (a *BasicAuth) makeLockoutRoom(now time.Time) {
+	if len(a.lockouts) < maxLockoutEntries {
+		return
+	}
+
+	for username, entry := range a.lockouts {
+		if now.Sub(entry.lastFailure) >= a.LockoutDuration && !now.Before(entry.lockedUntil) {
+			delete(a.lockouts, username)
+		}
+	}
+
+	// Locked out usernames are only evicted as a last resort, so an attacker cannot lift a lockout by flooding.
+	for _, evictLocked := range []bool{false, true} {
+		for username, entry := range a.lockouts {
+			if len(a.lockouts) < maxLockoutEntries-maxLockoutEntries/10 {
+				return
+			}
+
+			if evictLocked || !now.Before(entry.lockedUntil) {
+				delete(a.lockouts, username)
+			}
+		}
+	}
+}
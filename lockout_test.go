@@ -0,0 +1,125 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Tests that usernames are locked out after too many failed attempts, without calling the authenticator.
+func TestLockout(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	calls := 0
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso", "nicholasdwiarto": "nicholasdwiarto"})
+	auth.MaxFailures = 3
+	auth.LockoutDuration = time.Minute
+	auth.clock = func() time.Time { return now }
+	auth.Authenticator = func(username, password string) bool {
+		calls++
+		return auth.verifyUser(username, password)
+	}
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	steps := []struct {
+		name           string
+		username       string
+		password       string
+		elapsed        time.Duration
+		expectedStatus int
+		expectedCalls  int
+		expectedRetry  string
+	}{
+		{name: "test_first_failure", username: "gerysantoso", password: "wrong", expectedStatus: http.StatusUnauthorized, expectedCalls: 1},
+		{name: "test_second_failure", username: "gerysantoso", password: "wrong", expectedStatus: http.StatusUnauthorized, expectedCalls: 2},
+		{name: "test_success_resets_counter", username: "gerysantoso", password: "gerysantoso", expectedStatus: http.StatusOK, expectedCalls: 3},
+		{name: "test_failure_after_reset", username: "gerysantoso", password: "wrong", expectedStatus: http.StatusUnauthorized, expectedCalls: 4},
+		{name: "test_second_failure_after_reset", username: "gerysantoso", password: "wrong", expectedStatus: http.StatusUnauthorized, expectedCalls: 5},
+		{name: "test_third_failure_locks_out", username: "gerysantoso", password: "wrong", expectedStatus: http.StatusUnauthorized, expectedCalls: 6, expectedRetry: "60"},
+		{name: "test_locked_out_with_valid_credentials", username: "gerysantoso", password: "gerysantoso", elapsed: 30 * time.Second, expectedStatus: http.StatusUnauthorized, expectedCalls: 6, expectedRetry: "30"},
+		{name: "test_other_user_unaffected", username: "nicholasdwiarto", password: "nicholasdwiarto", expectedStatus: http.StatusOK, expectedCalls: 7},
+		{name: "test_lockout_expires", username: "gerysantoso", password: "gerysantoso", elapsed: 30 * time.Second, expectedStatus: http.StatusOK, expectedCalls: 8},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			now = now.Add(step.elapsed)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(step.username, step.password)
+			handler(w, r)
+
+			if step.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", step.expectedStatus, w.Code)
+			}
+
+			if calls != step.expectedCalls {
+				t.Errorf("Expected and actual authenticator calls are different! Expected: %v. Got: %v.", step.expectedCalls, calls)
+			}
+
+			if retry := w.Header().Get("Retry-After"); retry != step.expectedRetry {
+				t.Errorf("Expected and actual 'Retry-After' headers are different! Expected: %q. Got: %q.", step.expectedRetry, retry)
+			}
+		})
+	}
+
+	if rateLimited := auth.Stats().RateLimited; rateLimited != 1 {
+		t.Errorf("Expected and actual locked out requests are different! Expected: %v. Got: %v.", 1, rateLimited)
+	}
+}
+
+// Tests that failures are counted safely under concurrent requests, and that the tracked usernames stay bounded.
+func TestLockoutConcurrentAndBounded(t *testing.T) {
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.MaxFailures = 5
+	auth.LockoutDuration = time.Minute
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < maxLockoutEntries/4; j++ {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.SetBasicAuth("user"+strconv.Itoa(i)+"-"+strconv.Itoa(j), "wrong")
+				handler(httptest.NewRecorder(), r)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	auth.lockoutMu.Lock()
+	tracked := len(auth.lockouts)
+	auth.lockoutMu.Unlock()
+
+	if tracked > maxLockoutEntries {
+		t.Errorf("Expected at most %v tracked usernames. Got: %v.", maxLockoutEntries, tracked)
+	}
+}
+
+// Tests that `Retry-After` is sent for the normalized username the lockouts are keyed by, whatever the spelling of
+// the username sent by the client.
+func TestLockoutRetryAfterNormalized(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.MaxFailures = 1
+	auth.LockoutDuration = time.Minute
+	auth.UsernameCaseFold = true
+	auth.UsernameTrimSpace = true
+	auth.clock = func() time.Time { return now }
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	for _, username := range []string{"GerySantoso", " gerysantoso "} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		r.SetBasicAuth(username, "wrong")
+		handler(w, r)
+
+		if retry := w.Header().Get("Retry-After"); retry != "60" {
+			t.Errorf("Expected and actual 'Retry-After' headers of %q are different! Expected: %q. Got: %q.", username, "60", retry)
+		}
+	}
+}
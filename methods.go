@@ -0,0 +1,64 @@
+package basic
+
+import (
+	"net/http"
+	"strings"
+)
+
+// defaultSafeMethods are the safe methods of RFC 9110, which are only meant to read resources.
+var defaultSafeMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace}
+
+// standardMethods are the methods defined by RFC 9110 and RFC 5789. Other methods, such as `PROPFIND`, `MKCOL`,
+// `MOVE`, and `COPY` of WebDAV, are extension methods.
+var standardMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodConnect: {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+}
+
+// safeMethods returns the methods considered safe, which are `SafeMethods` if set, or the safe methods of RFC 9110.
+func (a *BasicAuth) safeMethods() []string {
+	if a.SafeMethods != nil {
+		return a.SafeMethods
+	}
+
+	return defaultSafeMethods
+}
+
+// isSafeMethod checks whether a method is listed in the safe methods. Methods are case-sensitive.
+func (a *BasicAuth) isSafeMethod(method string) bool {
+	for _, safe := range a.safeMethods() {
+		if method == safe {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isExtensionMethod checks whether a method is not one of the standard methods, so its semantics are unknown.
+func isExtensionMethod(method string) bool {
+	_, standard := standardMethods[method]
+
+	return !standard
+}
+
+// SendMethodNotAllowedResponse is used to send back a response if the method of the request is not safe while
+// `ReadOnly` is set. The `Allow` header lists the safe methods. This is not a challenge, so `WWW-Authenticate`
+// is not set.
+func (a *BasicAuth) SendMethodNotAllowedResponse(w http.ResponseWriter, r *http.Request) {
+	a.stripHeaders(w)
+	w.Header().Set("Allow", strings.Join(a.safeMethods(), ", "))
+	if a.MethodNotAllowedResponse == nil {
+		(&AuthError{Code: http.StatusMethodNotAllowed, Reason: methodNotAllowedMessage}).ServeHTTP(w, r)
+		return
+	}
+
+	a.MethodNotAllowedResponse.ServeHTTP(w, r)
+}
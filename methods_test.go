@@ -0,0 +1,59 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests that extension methods, such as the ones of WebDAV, are unsafe unless listed in `SafeMethods`.
+func TestSafeMethods(t *testing.T) {
+	tests := []struct {
+		name           string
+		readOnly       bool
+		safeMethods    []string
+		method         string
+		credentials    bool
+		expectedStatus int
+		expectedAllow  string
+	}{
+		{name: "test_read_only_get", readOnly: true, method: http.MethodGet, credentials: true, expectedStatus: http.StatusOK},
+		{name: "test_read_only_post", readOnly: true, method: http.MethodPost, credentials: true, expectedStatus: http.StatusMethodNotAllowed, expectedAllow: "GET, HEAD, OPTIONS, TRACE"},
+		{name: "test_read_only_propfind", readOnly: true, method: "PROPFIND", credentials: true, expectedStatus: http.StatusMethodNotAllowed, expectedAllow: "GET, HEAD, OPTIONS, TRACE"},
+		{name: "test_read_only_mkcol", readOnly: true, method: "MKCOL", credentials: true, expectedStatus: http.StatusMethodNotAllowed, expectedAllow: "GET, HEAD, OPTIONS, TRACE"},
+		{name: "test_read_only_propfind_configured_safe", readOnly: true, safeMethods: []string{http.MethodGet, "PROPFIND"}, method: "PROPFIND", credentials: true, expectedStatus: http.StatusOK},
+		{name: "test_read_only_mkcol_configured_safe_propfind", readOnly: true, safeMethods: []string{http.MethodGet, "PROPFIND"}, method: "MKCOL", credentials: true, expectedStatus: http.StatusMethodNotAllowed, expectedAllow: "GET, PROPFIND"},
+		{name: "test_unlisted_propfind_protected", method: "PROPFIND", credentials: false, expectedStatus: http.StatusUnauthorized},
+		{name: "test_unlisted_mkcol_protected", method: "MKCOL", credentials: false, expectedStatus: http.StatusUnauthorized},
+		{name: "test_unlisted_mkcol_with_credentials", method: "MKCOL", credentials: true, expectedStatus: http.StatusOK},
+		{name: "test_unlisted_propfind_configured_safe", safeMethods: []string{http.MethodGet, "PROPFIND"}, method: "PROPFIND", credentials: false, expectedStatus: http.StatusOK},
+		{name: "test_unlisted_standard_method_public", method: http.MethodPut, credentials: false, expectedStatus: http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			auth.ReadOnly = tc.readOnly
+			auth.SafeMethods = tc.safeMethods
+			handler := auth.AuthenticateMethods([]string{http.MethodPost, http.MethodDelete}, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(tc.method, "/", nil)
+			w := httptest.NewRecorder()
+			if tc.credentials {
+				r.SetBasicAuth("gerysantoso", "gerysantoso")
+			}
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if allow := w.Header().Get("Allow"); allow != tc.expectedAllow {
+				t.Errorf("Expected and actual 'Allow' headers are different! Expected: %v. Got: %v.", tc.expectedAllow, allow)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package basic
+
+import "time"
+
+// Metrics receives every authentication decision, so any metrics system can be plugged in without this package
+// depending on it. The result is one of the names used in the audit trail, such as `success`, `invalid_scheme`, or
+// `invalid_credentials`, and the duration is the time taken to decide, including the authenticator. Implementations
+// are called concurrently, and should not block.
+type Metrics interface {
+	IncResult(result string)
+	ObserveDuration(d time.Duration)
+}
+
+// observe reports an authentication decision, and the time it took, to `Metrics` if set.
+func (a *BasicAuth) observe(res result, elapsed time.Duration) {
+	if a.Metrics == nil {
+		return
+	}
+
+	a.Metrics.IncResult(res.String())
+	a.Metrics.ObserveDuration(elapsed)
+}
+
+// isSlow checks whether an authentication took longer than `SlowAuthThreshold`, if set.
+func (a *BasicAuth) isSlow(elapsed time.Duration) bool {
+	return a.SlowAuthThreshold > 0 && elapsed > a.SlowAuthThreshold
+}
@@ -0,0 +1,74 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMetrics records the calls made to `Metrics`.
+type fakeMetrics struct {
+	mu        sync.Mutex
+	results   []string
+	durations []time.Duration
+}
+
+func (m *fakeMetrics) IncResult(result string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = append(m.results, result)
+}
+
+func (m *fakeMetrics) ObserveDuration(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.durations = append(m.durations, d)
+}
+
+// Tests that every authentication decision is reported to `Metrics`.
+func TestMetrics(t *testing.T) {
+	metrics := &fakeMetrics{}
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.Metrics = metrics
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	requests := []struct {
+		username string
+		password string
+		header   string
+	}{
+		{username: "gerysantoso", password: "gerysantoso"},
+		{username: "gerysantoso", password: "wrong"},
+		{header: "Bearer token"},
+		{},
+	}
+
+	for _, req := range requests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if req.username != "" {
+			r.SetBasicAuth(req.username, req.password)
+		}
+		if req.header != "" {
+			r.Header.Set("Authorization", req.header)
+		}
+		handler(httptest.NewRecorder(), r)
+	}
+
+	expected := []string{"success", "invalid_credentials", "invalid_scheme", "missing_credentials"}
+	if !reflect.DeepEqual(metrics.results, expected) {
+		t.Errorf("Expected and actual results are different! Expected: %v. Got: %v.", expected, metrics.results)
+	}
+
+	if len(metrics.durations) != len(expected) {
+		t.Errorf("Expected and actual numbers of durations are different! Expected: %v. Got: %v.", len(expected), len(metrics.durations))
+	}
+
+	for _, d := range metrics.durations {
+		if d < 0 {
+			t.Errorf("Expected non-negative durations. Got: %v.", d)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package basic
+
+import "strings"
+
+// normalizeUsername runs a username through the normalization pipeline. The steps always run in the same order,
+// whatever the combination of steps enabled, so that they cannot interfere with each other in surprising ways:
+//
+//  1. `UsernameTrimSpace` removes leading and trailing white space.
+//  2. `UsernameCaseFold` folds the case, so that usernames are case-insensitive.
+//  3. `UsernameUnicodeNormalizer` normalizes the Unicode representation, such as to NFC.
+//  4. `UsernameTransform` applies a custom transformation, such as appending a domain suffix.
+//
+// The normalized username is then validated with `UsernamePattern`, and used for everything else: the lockout, the
+// authenticator, the audit trail, and `UsernameFromContext`. The keys of `Users` have to be normalized already.
+func (a *BasicAuth) normalizeUsername(username string) string {
+	if a.UsernameTrimSpace {
+		username = strings.TrimSpace(username)
+	}
+
+	if a.UsernameCaseFold {
+		username = strings.ToLower(username)
+	}
+
+	if a.UsernameUnicodeNormalizer != nil {
+		username = a.UsernameUnicodeNormalizer(username)
+	}
+
+	if a.UsernameTransform != nil {
+		username = a.UsernameTransform(username)
+	}
+
+	return username
+}
@@ -0,0 +1,112 @@
+package basic
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Tests the username normalization pipeline end to end, including the order of its steps.
+func TestUsernameNormalization(t *testing.T) {
+	// Composes a decomposed lowercase 'é' only, so the test fails if the case is folded after this step.
+	nfc := func(username string) string { return strings.ReplaceAll(username, "e\u0301", "\u00e9") }
+	suffix := func(username string) string { return username + "@example.com" }
+	pattern := regexp.MustCompile(`^\pL+@example\.com$`)
+
+	tests := []struct {
+		name             string
+		username         string
+		configure        func(auth *BasicAuth)
+		expectedStatus   int
+		expectedUsername string
+	}{
+		{
+			name:     "test_full_pipeline",
+			username: "  GE\u0301RY  ",
+			configure: func(auth *BasicAuth) {
+				auth.UsernameTrimSpace = true
+				auth.UsernameCaseFold = true
+				auth.UsernameUnicodeNormalizer = nfc
+				auth.UsernameTransform = suffix
+				auth.UsernamePattern = pattern
+			},
+			expectedStatus:   http.StatusOK,
+			expectedUsername: "géry@example.com",
+		},
+		{
+			name:     "test_without_trimming",
+			username: "  GE\u0301RY  ",
+			configure: func(auth *BasicAuth) {
+				auth.UsernameCaseFold = true
+				auth.UsernameUnicodeNormalizer = nfc
+				auth.UsernameTransform = suffix
+				auth.UsernamePattern = pattern
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:     "test_without_case_folding",
+			username: "  GE\u0301RY  ",
+			configure: func(auth *BasicAuth) {
+				auth.UsernameTrimSpace = true
+				auth.UsernameUnicodeNormalizer = nfc
+				auth.UsernameTransform = suffix
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:     "test_without_unicode_normalization",
+			username: "ge\u0301ry",
+			configure: func(auth *BasicAuth) {
+				auth.UsernameTransform = suffix
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:     "test_pattern_after_normalization",
+			username: " géry1 ",
+			configure: func(auth *BasicAuth) {
+				auth.UsernameTrimSpace = true
+				auth.UsernameTransform = suffix
+				auth.UsernamePattern = pattern
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:             "test_disabled",
+			username:         "géry@example.com",
+			configure:        func(auth *BasicAuth) {},
+			expectedStatus:   http.StatusOK,
+			expectedUsername: "géry@example.com",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"géry@example.com": "gerysantoso"})
+			tc.configure(auth)
+
+			var username string
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+				username, _ = UsernameFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(tc.username+":gerysantoso")))
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if tc.expectedUsername != username {
+				t.Errorf("Expected and actual usernames are different! Expected: %v. Got: %v.", tc.expectedUsername, username)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package basic
+
+import "net/http"
+
+// Option configures a `BasicAuth` created with `New`.
+type Option func(*BasicAuth)
+
+// New creates a `BasicAuth` with the default configurations of `NewDefaultBasicAuth`, and applies the options in order.
+// Unlike `NewCustomBasicAuth`, only the options that differ from the defaults have to be passed, for example:
+//
+//	auth := basic.New(basic.WithRealm("Private"), basic.WithUsers(users))
+func New(opts ...Option) *BasicAuth {
+	auth := NewDefaultBasicAuth(nil)
+	for _, opt := range opts {
+		opt(auth)
+	}
+
+	return auth
+}
+
+// WithAuthenticator sets the `Authenticator` attribute. A `nil` authenticator keeps the default one verifying `Users`.
+func WithAuthenticator(authenticator func(username, password string) bool) Option {
+	return func(a *BasicAuth) {
+		if authenticator != nil {
+			a.Authenticator = authenticator
+		}
+	}
+}
+
+// WithCharset sets the `Charset` attribute, which is sent in the challenge.
+func WithCharset(charset string) Option {
+	return func(a *BasicAuth) {
+		a.Charset = charset
+	}
+}
+
+// WithInvalidCredentialsResponse sets the `InvalidCredentialsResponse` attribute. A `nil` handler keeps the default one.
+func WithInvalidCredentialsResponse(handler http.Handler) Option {
+	return func(a *BasicAuth) {
+		if handler != nil {
+			a.InvalidCredentialsResponse = handler
+		}
+	}
+}
+
+// WithInvalidSchemeResponse sets the `InvalidSchemeResponse` attribute. A `nil` handler keeps the default one.
+func WithInvalidSchemeResponse(handler http.Handler) Option {
+	return func(a *BasicAuth) {
+		if handler != nil {
+			a.InvalidSchemeResponse = handler
+		}
+	}
+}
+
+// WithRealm sets the `Realm` attribute, which is sent in the challenge.
+func WithRealm(realm string) Option {
+	return func(a *BasicAuth) {
+		a.Realm = realm
+	}
+}
+
+// WithUsers sets the `Users` attribute, which is verified by the default authenticator.
+func WithUsers(users map[string]string) Option {
+	return func(a *BasicAuth) {
+		a.Users = users
+	}
+}
@@ -0,0 +1,99 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests that the options of `New` are applied, and that unset options keep the defaults.
+func TestNew(t *testing.T) {
+	users := map[string]string{"gerysantoso": "gerysantoso"}
+	teapot := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+
+	tests := []struct {
+		name              string
+		opts              []Option
+		header            string
+		expectedStatus    int
+		expectedChallenge string
+	}{
+		{
+			name:              "test_defaults",
+			opts:              nil,
+			header:            "Basic Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28=",
+			expectedStatus:    http.StatusUnauthorized,
+			expectedChallenge: "",
+		},
+		{
+			name:              "test_users_and_realm",
+			opts:              []Option{WithUsers(users), WithRealm("Private")},
+			header:            "Basic Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28=",
+			expectedStatus:    http.StatusOK,
+			expectedChallenge: "",
+		},
+		{
+			name:              "test_authenticator",
+			opts:              []Option{WithAuthenticator(func(username, password string) bool { return username == "gerysantoso" })},
+			header:            "Basic Z2VyeXNhbnRvc286d3Jvbmc=",
+			expectedStatus:    http.StatusOK,
+			expectedChallenge: "",
+		},
+		{
+			name:              "test_nil_authenticator_keeps_default",
+			opts:              []Option{WithUsers(users), WithAuthenticator(nil)},
+			header:            "Basic Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28=",
+			expectedStatus:    http.StatusOK,
+			expectedChallenge: "",
+		},
+		{
+			name:              "test_charset",
+			opts:              []Option{WithRealm("Private"), WithCharset("US-ASCII")},
+			header:            "",
+			expectedStatus:    http.StatusUnauthorized,
+			expectedChallenge: `Basic realm="Private", charset="US-ASCII"`,
+		},
+		{
+			name:              "test_invalid_credentials_response",
+			opts:              []Option{WithRealm("Private"), WithInvalidCredentialsResponse(teapot)},
+			header:            "Basic Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28=",
+			expectedStatus:    http.StatusTeapot,
+			expectedChallenge: `Basic realm="Private", charset="UTF-8"`,
+		},
+		{
+			name:              "test_invalid_scheme_response",
+			opts:              []Option{WithRealm("Private"), WithInvalidSchemeResponse(teapot)},
+			header:            "Bearer token",
+			expectedStatus:    http.StatusTeapot,
+			expectedChallenge: `Basic realm="Private", charset="UTF-8"`,
+		},
+		{
+			name:              "test_nil_responses_keep_defaults",
+			opts:              []Option{WithRealm("Private"), WithInvalidCredentialsResponse(nil), WithInvalidSchemeResponse(nil)},
+			header:            "Bearer token",
+			expectedStatus:    http.StatusUnauthorized,
+			expectedChallenge: `Basic realm="Private", charset="UTF-8"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := New(tc.opts...).Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			if tc.header != "" {
+				r.Header.Set("Authorization", tc.header)
+			}
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if challenge := w.Header().Get("WWW-Authenticate"); challenge != tc.expectedChallenge {
+				t.Errorf("Expected and actual challenges are different! Expected: %v. Got: %v.", tc.expectedChallenge, challenge)
+			}
+		})
+	}
+}
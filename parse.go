@@ -0,0 +1,144 @@
+package basic
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// parseCredentials extracts the username and the password from the `Authorization` header of a request. It
+// behaves like `(*http.Request).BasicAuth`, but decodes the credentials with `CredentialEncoding` if set. With
+// `LenientPercentDecode`, a header that cannot be parsed is percent-decoded and parsed again. If `Charset` is
+// ISO-8859-1, the credentials are converted to UTF-8, so they can be compared with the ones of `Users`.
+func (a *BasicAuth) parseCredentials(r *http.Request) (string, string, bool) {
+	username, password, ok := a.parseRawCredentials(r)
+	if ok && isLatin1(a.Charset) {
+		return decodeLatin1(username), decodeLatin1(password), true
+	}
+
+	return username, password, ok
+}
+
+// parseRawCredentials extracts the username and the password from the `Authorization` header of a request, without
+// any conversion of their charset.
+func (a *BasicAuth) parseRawCredentials(r *http.Request) (string, string, bool) {
+	encoding := base64.StdEncoding
+	if a.CredentialEncoding != nil {
+		encoding = a.CredentialEncoding
+	}
+
+	header := a.authorizationHeader(r)
+	username, password, ok := parseBasicAuth(header, encoding)
+	if ok || !a.LenientPercentDecode {
+		return username, password, ok
+	}
+
+	unescaped, err := url.QueryUnescape(header)
+	if err != nil || unescaped == header {
+		return "", "", false
+	}
+
+	return parseBasicAuth(unescaped, encoding)
+}
+
+// authorizationHeader returns the value of the header carrying the credentials of a request. Header names are
+// case-insensitive, so the name is canonicalized before the lookup, just like incoming headers are.
+func (a *BasicAuth) authorizationHeader(r *http.Request) string {
+	if a.HeaderName == "" && a.ProxyMode {
+		return r.Header.Get("Proxy-Authorization")
+	}
+
+	if a.HeaderName == "" {
+		return r.Header.Get("Authorization")
+	}
+
+	return r.Header.Get(textproto.CanonicalMIMEHeaderKey(a.HeaderName))
+}
+
+// defaultMaxCredentialLength is the default `MaxCredentialLength`, which is way longer than any legitimate header.
+const defaultMaxCredentialLength = 8 << 10
+
+// maxDecodedCredentialLen is the size of the pooled buffers used to decode credentials. Longer credentials are
+// still accepted, but decoded in a buffer of their own.
+const maxDecodedCredentialLen = 1024
+
+// credentialBuffers pools the scratch buffers used to decode credentials. Each buffer holds the encoded credentials
+// in its first half, and the decoded ones in its second half.
+var credentialBuffers = sync.Pool{
+	New: func() interface{} {
+		buffer := make([]byte, 2*maxDecodedCredentialLen)
+		return &buffer
+	},
+}
+
+// releaseCredentialBuffer puts a zeroed buffer back in `credentialBuffers`. Tests replace it to inspect the buffers
+// as they are released, whether or not the pool hands them out again.
+var releaseCredentialBuffer = func(buffer *[]byte) {
+	credentialBuffers.Put(buffer)
+}
+
+// cutScheme returns the parameters of the value of an `Authorization` header with the given scheme, without the white
+// space around them. The scheme is case-insensitive (RFC 7235), and has to be followed by at least one space or tab.
+func cutScheme(header, scheme string) (string, bool) {
+	if len(header) <= len(scheme) || !strings.EqualFold(header[:len(scheme)], scheme) {
+		return "", false
+	}
+
+	if c := header[len(scheme)]; c != ' ' && c != '\t' {
+		return "", false
+	}
+
+	return strings.Trim(header[len(scheme):], " \t"), true
+}
+
+// parseBasicAuth parses the value of an `Authorization` header with the `Basic` scheme. The scheme is
+// case-insensitive (RFC 7235), and has to be followed by at least one space or tab. Extra white space around the
+// credentials is tolerated. The credentials are decoded with the given encoding, and have to be in the
+// `username:password` format.
+func parseBasicAuth(header string, encoding *base64.Encoding) (string, string, bool) {
+	encoded, ok := cutScheme(header, "Basic")
+	if !ok {
+		return "", "", false
+	}
+
+	if len(encoded) > maxDecodedCredentialLen {
+		decoded, err := encoding.DecodeString(encoded)
+		defer zero(decoded)
+		if err != nil {
+			return "", "", false
+		}
+
+		return splitCredentials(string(decoded))
+	}
+
+	// Decode in a pooled buffer. The bytes written to it are zeroed before it is put back, so that no credentials
+	// linger in reused memory.
+	buffer := credentialBuffers.Get().(*[]byte)
+	src := (*buffer)[:copy(*buffer, encoded)]
+	dst := (*buffer)[maxDecodedCredentialLen : maxDecodedCredentialLen+encoding.DecodedLen(len(src))]
+	defer func() {
+		zero(src)
+		zero(dst)
+		releaseCredentialBuffer(buffer)
+	}()
+
+	n, err := encoding.Decode(dst, src)
+	if err != nil {
+		return "", "", false
+	}
+
+	return splitCredentials(string(dst[:n]))
+}
+
+// splitCredentials splits decoded credentials in the `username:password` format.
+func splitCredentials(credentials string) (string, string, bool) {
+	username, password, ok := strings.Cut(credentials, ":")
+	if !ok {
+		return "", "", false
+	}
+
+	return username, password, true
+}
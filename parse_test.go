@@ -0,0 +1,388 @@
+package basic
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Tests the parser of the `Authorization` header.
+func TestParseBasicAuth(t *testing.T) {
+	tests := []struct {
+		name             string
+		header           string
+		expectedUsername string
+		expectedPassword string
+		expectedOK       bool
+	}{
+		{
+			name:             "test_valid",
+			header:           "Basic " + base64.StdEncoding.EncodeToString([]byte("gerysantoso:gerysantoso")),
+			expectedUsername: "gerysantoso",
+			expectedPassword: "gerysantoso",
+			expectedOK:       true,
+		},
+		{
+			name:             "test_colon_in_password",
+			header:           "Basic " + base64.StdEncoding.EncodeToString([]byte("gerysantoso:pass:word")),
+			expectedUsername: "gerysantoso",
+			expectedPassword: "pass:word",
+			expectedOK:       true,
+		},
+		{
+			name:             "test_longer_than_pooled_buffers",
+			header:           "Basic " + base64.StdEncoding.EncodeToString([]byte("gerysantoso:"+strings.Repeat("a", 2*maxDecodedCredentialLen))),
+			expectedUsername: "gerysantoso",
+			expectedPassword: strings.Repeat("a", 2*maxDecodedCredentialLen),
+			expectedOK:       true,
+		},
+		{
+			name:             "test_lowercase_scheme",
+			header:           "basic " + base64.StdEncoding.EncodeToString([]byte("gerysantoso:gerysantoso")),
+			expectedUsername: "gerysantoso",
+			expectedPassword: "gerysantoso",
+			expectedOK:       true,
+		},
+		{
+			name:             "test_uppercase_scheme",
+			header:           "BASIC " + base64.StdEncoding.EncodeToString([]byte("gerysantoso:gerysantoso")),
+			expectedUsername: "gerysantoso",
+			expectedPassword: "gerysantoso",
+			expectedOK:       true,
+		},
+		{
+			name:             "test_tab_after_scheme",
+			header:           "Basic\t" + base64.StdEncoding.EncodeToString([]byte("gerysantoso:gerysantoso")),
+			expectedUsername: "gerysantoso",
+			expectedPassword: "gerysantoso",
+			expectedOK:       true,
+		},
+		{
+			name:             "test_extra_white_space",
+			header:           "Basic  \t " + base64.StdEncoding.EncodeToString([]byte("gerysantoso:gerysantoso")) + " ",
+			expectedUsername: "gerysantoso",
+			expectedPassword: "gerysantoso",
+			expectedOK:       true,
+		},
+		{
+			name:       "test_no_space_after_scheme",
+			header:     "Basic" + base64.StdEncoding.EncodeToString([]byte("gerysantoso:gerysantoso")),
+			expectedOK: false,
+		},
+		{
+			name:       "test_scheme_only",
+			header:     "Basic",
+			expectedOK: false,
+		},
+		{
+			name:       "test_blank_credentials",
+			header:     "basic   ",
+			expectedOK: false,
+		},
+		{
+			name:       "test_white_space_inside_credentials",
+			header:     "Basic Z2VyeXNhbnRvc28 6Z2VyeXNhbnRvc28=",
+			expectedOK: false,
+		},
+		{
+			name:       "test_longer_scheme",
+			header:     "Basically " + base64.StdEncoding.EncodeToString([]byte("gerysantoso:gerysantoso")),
+			expectedOK: false,
+		},
+		{
+			name:       "test_missing_colon_lowercase_scheme",
+			header:     "basic " + base64.StdEncoding.EncodeToString([]byte("gerysantoso")),
+			expectedOK: false,
+		},
+		{
+			name:       "test_missing_colon",
+			header:     "Basic " + base64.StdEncoding.EncodeToString([]byte("gerysantoso")),
+			expectedOK: false,
+		},
+		{
+			name:       "test_invalid_base64",
+			header:     "Basic !!!",
+			expectedOK: false,
+		},
+		{
+			name:       "test_other_scheme",
+			header:     "Bearer token",
+			expectedOK: false,
+		},
+		{
+			name:       "test_empty",
+			header:     "",
+			expectedOK: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			username, password, ok := parseBasicAuth(tc.header, base64.StdEncoding)
+			if ok != tc.expectedOK || username != tc.expectedUsername || password != tc.expectedPassword {
+				t.Errorf("Unexpected parsing result! Expected: (%v, %v, %v). Got: (%v, %v, %v).", tc.expectedUsername, tc.expectedPassword, tc.expectedOK, username, password, ok)
+			}
+		})
+	}
+}
+
+// Tests the decoding of credentials with a custom Base64 alphabet.
+func TestCredentialEncoding(t *testing.T) {
+	custom := base64.NewEncoding("zyxwvutsrqponmlkjihgfedcbaZYXWVUTSRQPONMLKJIHGFEDCBA9876543210-_")
+	header := "Basic " + custom.EncodeToString([]byte("gerysantoso:gerysantoso"))
+
+	tests := []struct {
+		name           string
+		encoding       *base64.Encoding
+		expectedStatus int
+	}{
+		{
+			name:           "test_custom_encoding",
+			encoding:       custom,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_default_encoding",
+			encoding:       nil,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			auth.CredentialEncoding = tc.encoding
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.Header.Set("Authorization", header)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests that percent-encoded headers are only accepted with `LenientPercentDecode`.
+func TestLenientPercentDecode(t *testing.T) {
+	tests := []struct {
+		name           string
+		header         string
+		lenient        bool
+		expectedStatus int
+	}{
+		{
+			name:           "test_percent_encoded_lenient",
+			header:         "Basic%20Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28%3D",
+			lenient:        true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_percent_encoded_strict",
+			header:         "Basic%20Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28%3D",
+			lenient:        false,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_plain_header_lenient",
+			header:         "Basic Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28=",
+			lenient:        true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_invalid_escape_lenient",
+			header:         "Basic%2",
+			lenient:        true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			auth.LenientPercentDecode = tc.lenient
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.Header.Set("Authorization", tc.header)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests that oversized headers are rejected with `MaxCredentialLength` before being decoded.
+func TestMaxCredentialLength(t *testing.T) {
+	long := "Basic " + base64.StdEncoding.EncodeToString([]byte("gerysantoso:"+strings.Repeat("a", 16<<10)))
+	tests := []struct {
+		name           string
+		maxLength      int
+		header         string
+		expectedStatus int
+	}{
+		{
+			name:           "test_default_limit",
+			maxLength:      defaultMaxCredentialLength,
+			header:         long,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_within_limit",
+			maxLength:      defaultMaxCredentialLength,
+			header:         "Basic Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28=",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_unlimited",
+			maxLength:      0,
+			header:         long,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			auth.Authenticator = func(username, password string) bool { return username == "gerysantoso" }
+			auth.MaxCredentialLength = tc.maxLength
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.Header.Set("Authorization", tc.header)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests that an enormous header is rejected early, without allocating memory in proportion to its length.
+func TestMaxCredentialLengthAllocations(t *testing.T) {
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Basic "+strings.Repeat("A", 8<<20))
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	runtime.ReadMemStats(&after)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusUnauthorized, w.Code)
+	}
+
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Expected the header to be rejected without decoding it! Allocated: %v bytes.", allocated)
+	}
+}
+
+// Tests the lookup of the credentials in a custom header, whatever the casing of its name.
+func TestHeaderName(t *testing.T) {
+	credentials := base64.StdEncoding.EncodeToString([]byte("gerysantoso:gerysantoso"))
+	tests := []struct {
+		name           string
+		headerName     string
+		rawHeader      string
+		expectedStatus int
+	}{
+		{
+			name:           "test_lowercase_header_on_the_wire",
+			headerName:     "X-Original-Authorization",
+			rawHeader:      "x-original-authorization: Basic " + credentials,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_lowercase_header_name_option",
+			headerName:     "x-original-authorization",
+			rawHeader:      "X-Original-Authorization: Basic " + credentials,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_uppercase_both",
+			headerName:     "X-ORIGINAL-AUTHORIZATION",
+			rawHeader:      "X-ORIGINAL-AUTHORIZATION: Basic " + credentials,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_default_header_ignored",
+			headerName:     "X-Original-Authorization",
+			rawHeader:      "Authorization: Basic " + credentials,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_default_header_name",
+			headerName:     "",
+			rawHeader:      "authorization: Basic " + credentials,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			auth.HeaderName = tc.headerName
+
+			// Read the request from the wire, so the header is sent exactly as written.
+			raw := "GET / HTTP/1.1\r\nHost: localhost\r\n" + tc.rawHeader + "\r\n\r\n"
+			r, err := http.ReadRequest(bufio.NewReader(strings.NewReader(raw)))
+			if err != nil {
+				t.Fatalf("Failed to read the request: %v.", err)
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			w := httptest.NewRecorder()
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests the extraction of a password containing colons, as seen by the authenticator.
+func TestDebugCredentialSink(t *testing.T) {
+	var username, password string
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "pass:word:with:colons"})
+	auth.debugCredentialSink = func(u, p string) {
+		username, password = u, p
+	}
+
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	r.SetBasicAuth("gerysantoso", "pass:word:with:colons")
+
+	handler(w, r)
+
+	if username != "gerysantoso" || password != "pass:word:with:colons" {
+		t.Errorf("Unexpected extracted credentials! Got: (%v, %v).", username, password)
+	}
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusOK, w.Code)
+	}
+}
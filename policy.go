@@ -0,0 +1,64 @@
+package basic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrWeakPassword is returned when a password does not satisfy a `PasswordPolicy`. The returned errors wrap it, and
+// describe every requirement which is not met.
+var ErrWeakPassword = errors.New("basic: weak password")
+
+// PasswordPolicy is a set of complexity requirements for the passwords of new credentials, such as the ones passed to
+// `AddUser`. It is only checked when credentials are provisioned, never when they are verified, so it does not
+// affect the requests. The zero value accepts any password.
+type PasswordPolicy struct {
+	MinPasswordLen   int  // Minimum number of characters (not bytes) of a password. No minimum if zero or negative.
+	RequireMixedCase bool // Require both an uppercase and a lowercase letter.
+	RequireDigits    bool // Require at least one digit.
+	RequireSymbols   bool // Require at least one punctuation character or symbol, such as `!` or `$`.
+}
+
+// Check verifies a password against the policy, and returns an error wrapping `ErrWeakPassword` with every
+// requirement it does not meet, or `nil` if it meets all of them.
+func (p PasswordPolicy) Check(password string) error {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var violations []string
+	if length := utf8.RuneCountInString(password); p.MinPasswordLen > 0 && length < p.MinPasswordLen {
+		violations = append(violations, fmt.Sprintf("be at least %d characters long (got %d)", p.MinPasswordLen, length))
+	}
+
+	if p.RequireMixedCase && !(upper && lower) {
+		violations = append(violations, "contain both uppercase and lowercase letters")
+	}
+
+	if p.RequireDigits && !digit {
+		violations = append(violations, "contain a digit")
+	}
+
+	if p.RequireSymbols && !symbol {
+		violations = append(violations, "contain a symbol")
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: the password has to %s", ErrWeakPassword, strings.Join(violations, ", "))
+}
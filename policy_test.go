@@ -0,0 +1,83 @@
+package basic
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Tests checking passwords against a policy, with a description of every requirement which is not met.
+func TestPasswordPolicy(t *testing.T) {
+	strict := PasswordPolicy{MinPasswordLen: 12, RequireMixedCase: true, RequireDigits: true, RequireSymbols: true}
+
+	tests := []struct {
+		name           string
+		policy         PasswordPolicy
+		password       string
+		expectedErrors []string
+	}{
+		{
+			name:           "test_zero_policy",
+			policy:         PasswordPolicy{},
+			password:       "",
+			expectedErrors: nil,
+		},
+		{
+			name:           "test_strong_password",
+			policy:         strict,
+			password:       "Correct-Horse-9",
+			expectedErrors: nil,
+		},
+		{
+			name:           "test_too_short",
+			policy:         PasswordPolicy{MinPasswordLen: 12},
+			password:       "Short-9",
+			expectedErrors: []string{"at least 12 characters long (got 7)"},
+		},
+		{
+			name:           "test_length_in_characters",
+			policy:         PasswordPolicy{MinPasswordLen: 4},
+			password:       "café",
+			expectedErrors: nil,
+		},
+		{
+			name:           "test_single_case",
+			policy:         PasswordPolicy{RequireMixedCase: true},
+			password:       "correct-horse-9",
+			expectedErrors: []string{"uppercase and lowercase"},
+		},
+		{
+			name:     "test_every_requirement_missing",
+			policy:   strict,
+			password: "password",
+			expectedErrors: []string{
+				"at least 12 characters",
+				"uppercase and lowercase",
+				"a digit",
+				"a symbol",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Check(tc.password)
+			if len(tc.expectedErrors) == 0 {
+				if err != nil {
+					t.Errorf("Expected the password to satisfy the policy. Got: %v.", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrWeakPassword) {
+				t.Fatalf("Expected and actual errors are different! Expected: %v. Got: %v.", ErrWeakPassword, err)
+			}
+
+			for _, expected := range tc.expectedErrors {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("Expected the error to contain %q. Got: %v.", expected, err)
+				}
+			}
+		})
+	}
+}
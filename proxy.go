@@ -0,0 +1,40 @@
+package basic
+
+import "net/http"
+
+// proxyResponseWriter turns the failure responses of an origin server into the ones of a forward proxy, as described
+// in RFC 7235: `401 Unauthorized` becomes `407 Proxy Authentication Required`, and the challenge is moved from
+// `WWW-Authenticate` to `Proxy-Authenticate`. This applies to custom failure responses as well.
+type proxyResponseWriter struct {
+	http.ResponseWriter
+}
+
+// WriteHeader moves the challenge and sends the proxy status code instead of `401 Unauthorized`.
+func (w proxyResponseWriter) WriteHeader(code int) {
+	moveChallenge(w.Header())
+	if code == http.StatusUnauthorized {
+		code = http.StatusProxyAuthRequired
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write moves the challenge before the headers are implicitly sent, if `WriteHeader` has not been called.
+func (w proxyResponseWriter) Write(b []byte) (int, error) {
+	moveChallenge(w.Header())
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the original response writer, for `http.ResponseController`.
+func (w proxyResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// moveChallenge moves the values of `WWW-Authenticate` to `Proxy-Authenticate`.
+func moveChallenge(header http.Header) {
+	if challenges := header.Values("WWW-Authenticate"); len(challenges) > 0 {
+		header.Del("WWW-Authenticate")
+		header["Proxy-Authenticate"] = challenges
+	}
+}
@@ -0,0 +1,121 @@
+package basic
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Tests the forward proxy flow with `Proxy-Authorization`, `Proxy-Authenticate`, and `407 Proxy Authentication Required`.
+func TestProxyMode(t *testing.T) {
+	const credentials = "Basic Z2VyeXNhbnRvc286Z2VyeXNhbnRvc28="
+
+	tests := []struct {
+		name              string
+		header            string
+		value             string
+		customResponse    bool
+		maxPasswordLen    int
+		expectedStatus    int
+		expectedChallenge bool
+	}{
+		{
+			name:              "test_missing_credentials",
+			expectedStatus:    http.StatusProxyAuthRequired,
+			expectedChallenge: true,
+		},
+		{
+			name:           "test_valid_credentials",
+			header:         "Proxy-Authorization",
+			value:          credentials,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:              "test_authorization_ignored",
+			header:            "Authorization",
+			value:             credentials,
+			expectedStatus:    http.StatusProxyAuthRequired,
+			expectedChallenge: true,
+		},
+		{
+			name:              "test_custom_response",
+			header:            "Proxy-Authorization",
+			value:             "Basic Z2VyeXNhbnRvc286d3Jvbmc=",
+			customResponse:    true,
+			expectedStatus:    http.StatusProxyAuthRequired,
+			expectedChallenge: true,
+		},
+		{
+			name:           "test_other_status_kept",
+			header:         "Proxy-Authorization",
+			value:          credentials,
+			maxPasswordLen: 1,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Proxy", map[string]string{"gerysantoso": "gerysantoso"})
+			auth.ProxyMode = true
+			auth.MaxPasswordLen = tc.maxPasswordLen
+			if tc.customResponse {
+				auth.InvalidCredentialsResponse = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte("Denied!"))
+				})
+			}
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			w := httptest.NewRecorder()
+			if tc.header != "" {
+				r.Header.Set(tc.header, tc.value)
+			}
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			if challenge := w.Header().Get("Proxy-Authenticate"); (challenge != "") != tc.expectedChallenge {
+				t.Errorf("Expected and actual presences of the challenge are different! Expected: %v. Got: %v.", tc.expectedChallenge, challenge)
+			}
+
+			if challenge := w.Header().Get("WWW-Authenticate"); challenge != "" {
+				t.Errorf("Expected no origin server challenge! Got: %v.", challenge)
+			}
+		})
+	}
+}
+
+// Tests that `Verify` returns a proxy challenge in proxy mode.
+func TestProxyModeVerify(t *testing.T) {
+	auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Proxy", nil)
+	auth.ProxyMode = true
+
+	err := auth.Verify(httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Expected an authentication error! Got: %v.", err)
+	}
+
+	w := httptest.NewRecorder()
+	authErr.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+
+	if w.Code != http.StatusProxyAuthRequired {
+		t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusProxyAuthRequired, w.Code)
+	}
+
+	if challenge := w.Header().Get("Proxy-Authenticate"); !strings.HasPrefix(challenge, "Basic ") {
+		t.Errorf("Expected a proxy challenge! Got: %v.", challenge)
+	}
+
+	if challenge := w.Header().Get("WWW-Authenticate"); challenge != "" {
+		t.Errorf("Expected no origin server challenge! Got: %v.", challenge)
+	}
+}
@@ -0,0 +1,61 @@
+package basic
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// requestIDHeader is the header carrying correlation IDs, both in requests and in failure responses.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen is the maximum length of an incoming correlation ID. Longer IDs are replaced.
+const maxRequestIDLen = 128
+
+// requestIDContextKey is the key of the correlation ID of a request stored in a context.
+type requestIDContextKey struct{}
+
+// RequestIDFromContext returns the correlation ID of a request, if `RequestIDs` is set.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDContextKey{}).(string)
+
+	return id, ok
+}
+
+// withRequestID returns the request with its correlation ID in its context, if `RequestIDs` is set. The incoming
+// `X-Request-ID` is kept if it is a short token, so it cannot inject anything into headers or logs, and a random
+// ID is generated otherwise.
+func (a *BasicAuth) withRequestID(r *http.Request) *http.Request {
+	if !a.RequestIDs {
+		return r
+	}
+
+	if _, ok := RequestIDFromContext(r.Context()); ok {
+		return r
+	}
+
+	id := r.Header.Get(requestIDHeader)
+	if len(id) > maxRequestIDLen || !isToken(id) {
+		id = newRequestID()
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), requestIDContextKey{}, id))
+}
+
+// setRequestID sets the correlation ID of a request on its response, if any.
+func setRequestID(w http.ResponseWriter, r *http.Request) {
+	if id, ok := RequestIDFromContext(r.Context()); ok {
+		w.Header().Set(requestIDHeader, id)
+	}
+}
+
+// newRequestID generates a random correlation ID.
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic("basic: failed to generate a random request ID: " + err.Error())
+	}
+
+	return hex.EncodeToString(id)
+}
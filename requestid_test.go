@@ -0,0 +1,94 @@
+package basic
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests that correlation IDs are echoed on failure responses, and recorded in the audit trail and the events of
+// `Logger`, with the same time of the decision.
+func TestRequestIDs(t *testing.T) {
+	now := time.Date(2023, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		incoming       string
+		password       string
+		expectedStatus int
+		expectedHeader bool
+		expectedEchoed bool
+	}{
+		{name: "test_incoming_id_echoed", incoming: "abc123", password: "wrong", expectedStatus: http.StatusUnauthorized, expectedHeader: true, expectedEchoed: true},
+		{name: "test_missing_id_generated", incoming: "", password: "wrong", expectedStatus: http.StatusUnauthorized, expectedHeader: true},
+		{name: "test_unsafe_id_replaced", incoming: "abc\"123", password: "wrong", expectedStatus: http.StatusUnauthorized, expectedHeader: true},
+		{name: "test_too_long_id_replaced", incoming: strings.Repeat("a", maxRequestIDLen+1), password: "wrong", expectedStatus: http.StatusUnauthorized, expectedHeader: true},
+		{name: "test_success_without_header", incoming: "abc123", password: "gerysantoso", expectedStatus: http.StatusOK, expectedHeader: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+			auth.AuditWriter = buf
+			auth.RequestIDs = true
+			auth.clock = func() time.Time { return now }
+
+			var event AuthEvent
+			auth.Logger = func(e AuthEvent) { event = e }
+
+			var downstreamID string
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+				downstreamID, _ = RequestIDFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			if tc.incoming != "" {
+				r.Header.Set("X-Request-ID", tc.incoming)
+			}
+			r.SetBasicAuth("gerysantoso", tc.password)
+			handler(w, r)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+
+			auth.FlushAudit()
+			record := auditRecord{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+
+			if record.RequestID == "" || !isToken(record.RequestID) {
+				t.Errorf("Expected a valid request ID in the audit trail. Got: %q.", record.RequestID)
+			}
+
+			if event.RequestID != record.RequestID {
+				t.Errorf("Expected and actual request IDs of the event are different! Expected: %v. Got: %v.", record.RequestID, event.RequestID)
+			}
+
+			if !record.Timestamp.Equal(now) || !event.Timestamp.Equal(now) {
+				t.Errorf("Expected the audit record and the event to be stamped by the clock! Expected: %v. Got: %v and %v.", now, record.Timestamp, event.Timestamp)
+			}
+
+			header := w.Header().Get("X-Request-ID")
+			if tc.expectedHeader && header != record.RequestID {
+				t.Errorf("Expected and actual request IDs are different! Expected: %v. Got: %v.", record.RequestID, header)
+			}
+
+			if !tc.expectedHeader && (header != "" || downstreamID != record.RequestID) {
+				t.Errorf("Expected the request ID to be passed downstream only. Got header %q and downstream %q.", header, downstreamID)
+			}
+
+			if tc.expectedEchoed && record.RequestID != tc.incoming {
+				t.Errorf("Expected and actual request IDs are different! Expected: %v. Got: %v.", tc.incoming, record.RequestID)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package basic
+
+import "sync/atomic"
+
+// Stats is a snapshot of the number of authentication decisions made by a `BasicAuth` instance, for
+// lightweight observability without any metrics system.
+type Stats struct {
+	Success            uint64 // Requests that were successfully authenticated.
+	InvalidScheme      uint64 // Requests without credentials, with a malformed or non-Basic scheme, with over-length fields, with an unsupported method or body, or over plaintext HTTP.
+	InvalidCredentials uint64 // Requests with invalid or compromised credentials.
+	RateLimited        uint64 // Requests rejected by brute-force protection, as their username is locked out (see `MaxFailures`).
+	AuditDropped       uint64 // Audit records dropped as `AuditWriter` could not keep up. These are not authentication decisions.
+	Errors             uint64 // Requests that failed with an internal error, such as an error of `AuthenticatorE`, a timeout of `AuthenticatorCtx`, or a recovered panic (see `RecoverNext`).
+}
+
+// statsCounters are the counters behind `Stats`. They are only accessed atomically.
+type statsCounters struct {
+	success            uint64
+	invalidScheme      uint64
+	invalidCredentials uint64
+	rateLimited        uint64
+	auditDropped       uint64
+	errors             uint64
+}
+
+// count increments the counter of an authentication outcome.
+func (s *statsCounters) count(res result) {
+	switch res {
+	case resultSuccess:
+		atomic.AddUint64(&s.success, 1)
+	case resultInvalidCredentials, resultCompromisedPassword, resultReplayedCode:
+		atomic.AddUint64(&s.invalidCredentials, 1)
+	case resultLockedOut:
+		atomic.AddUint64(&s.rateLimited, 1)
+	case resultAuthenticatorError, resultUnavailable:
+		atomic.AddUint64(&s.errors, 1)
+	default:
+		atomic.AddUint64(&s.invalidScheme, 1)
+	}
+}
+
+// Stats returns a snapshot of the number of authentication decisions made so far.
+func (a *BasicAuth) Stats() Stats {
+	return Stats{
+		Success:            atomic.LoadUint64(&a.stats.success),
+		InvalidScheme:      atomic.LoadUint64(&a.stats.invalidScheme),
+		InvalidCredentials: atomic.LoadUint64(&a.stats.invalidCredentials),
+		RateLimited:        atomic.LoadUint64(&a.stats.rateLimited),
+		AuditDropped:       atomic.LoadUint64(&a.stats.auditDropped),
+		Errors:             atomic.LoadUint64(&a.stats.errors),
+	}
+}
+
+// ResetStats sets all the counters back to zero. This is mostly useful for tests.
+func (a *BasicAuth) ResetStats() {
+	atomic.StoreUint64(&a.stats.success, 0)
+	atomic.StoreUint64(&a.stats.invalidScheme, 0)
+	atomic.StoreUint64(&a.stats.invalidCredentials, 0)
+	atomic.StoreUint64(&a.stats.rateLimited, 0)
+	atomic.StoreUint64(&a.stats.auditDropped, 0)
+	atomic.StoreUint64(&a.stats.errors, 0)
+}
@@ -0,0 +1,64 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Tests that the counters track every kind of decision.
+func TestStats(t *testing.T) {
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.RecoverNext = true
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/panic" {
+			panic("downstream failure")
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	requests := []struct {
+		path     string
+		header   string
+		username string
+		password string
+	}{
+		{path: "/", username: "gerysantoso", password: "gerysantoso"},
+		{path: "/", username: "gerysantoso", password: "gerysantoso"},
+		{path: "/", username: "gerysantoso", password: "wrong_password"},
+		{path: "/", header: "Bearer token"},
+		{path: "/"},
+		{path: "/panic", username: "gerysantoso", password: "gerysantoso"},
+	}
+
+	var wg sync.WaitGroup
+	for _, req := range requests {
+		wg.Add(1)
+		go func(path, header, username, password string) {
+			defer wg.Done()
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			if header != "" {
+				r.Header.Set("Authorization", header)
+			}
+
+			if username != "" {
+				r.SetBasicAuth(username, password)
+			}
+
+			handler(httptest.NewRecorder(), r)
+		}(req.path, req.header, req.username, req.password)
+	}
+	wg.Wait()
+
+	expected := Stats{Success: 3, InvalidScheme: 2, InvalidCredentials: 1, RateLimited: 0, Errors: 1}
+	if stats := auth.Stats(); stats != expected {
+		t.Errorf("Expected and actual stats are different! Expected: %+v. Got: %+v.", expected, stats)
+	}
+
+	auth.ResetStats()
+	if stats := auth.Stats(); stats != (Stats{}) {
+		t.Errorf("Expected all stats to be reset, got: %+v.", stats)
+	}
+}
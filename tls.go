@@ -0,0 +1,32 @@
+package basic
+
+import (
+	"net/http"
+	"strings"
+)
+
+// isInsecure checks whether a request has been received over plaintext HTTP while `RequireTLS` is set. Requests
+// forwarded by a trusted reverse proxy terminating TLS are recognized with `ForwardedProtoHeader`.
+func (a *BasicAuth) isInsecure(r *http.Request) bool {
+	if !a.RequireTLS || r.TLS != nil {
+		return false
+	}
+
+	if a.ForwardedProtoHeader != "" && strings.EqualFold(strings.TrimSpace(r.Header.Get(a.ForwardedProtoHeader)), "https") {
+		return false
+	}
+
+	return true
+}
+
+// SendInsecureTransportResponse is used to send back a response if the request has been received over plaintext
+// HTTP while `RequireTLS` is set. Sending credentials again would not help, so `WWW-Authenticate` is not set.
+func (a *BasicAuth) SendInsecureTransportResponse(w http.ResponseWriter, r *http.Request) {
+	a.stripHeaders(w)
+	if a.InsecureTransportResponse == nil {
+		(&AuthError{Code: http.StatusForbidden, Reason: insecureTransportMessage}).ServeHTTP(w, r)
+		return
+	}
+
+	a.InsecureTransportResponse.ServeHTTP(w, r)
+}
@@ -0,0 +1,103 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests that `RequireTLS` rejects plaintext requests before reading their credentials, with both TLS and plain servers.
+func TestRequireTLS(t *testing.T) {
+	tests := []struct {
+		name                 string
+		tls                  bool
+		requireTLS           bool
+		forwardedProtoHeader string
+		forwardedProto       string
+		expectedStatus       int
+	}{
+		{
+			name:           "test_tls_server",
+			tls:            true,
+			requireTLS:     true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_plain_server",
+			tls:            false,
+			requireTLS:     true,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "test_plain_server_not_required",
+			tls:            false,
+			requireTLS:     false,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:                 "test_trusted_forwarded_proto",
+			tls:                  false,
+			requireTLS:           true,
+			forwardedProtoHeader: "X-Forwarded-Proto",
+			forwardedProto:       "HTTPS",
+			expectedStatus:       http.StatusOK,
+		},
+		{
+			name:                 "test_trusted_forwarded_proto_http",
+			tls:                  false,
+			requireTLS:           true,
+			forwardedProtoHeader: "X-Forwarded-Proto",
+			forwardedProto:       "http",
+			expectedStatus:       http.StatusForbidden,
+		},
+		{
+			name:           "test_untrusted_forwarded_proto",
+			tls:            false,
+			requireTLS:     true,
+			forwardedProto: "https",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var sink []string
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Private", map[string]string{"gerysantoso": "gerysantoso"})
+			auth.RequireTLS = tc.requireTLS
+			auth.ForwardedProtoHeader = tc.forwardedProtoHeader
+			auth.debugCredentialSink = func(username, password string) { sink = append(sink, username) }
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			server := httptest.NewUnstartedServer(handler)
+			if tc.tls {
+				server.StartTLS()
+			} else {
+				server.Start()
+			}
+			defer server.Close()
+
+			r, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.SetBasicAuth("gerysantoso", "gerysantoso")
+			if tc.forwardedProto != "" {
+				r.Header.Set("X-Forwarded-Proto", tc.forwardedProto)
+			}
+
+			res, err := server.Client().Do(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+
+			if tc.expectedStatus != res.StatusCode {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, res.StatusCode)
+			}
+
+			if res.StatusCode == http.StatusForbidden && (len(sink) != 0 || res.Header.Get("WWW-Authenticate") != "") {
+				t.Errorf("Expected the credentials not to be read, and no challenge to be sent! Got: %v, %q.", sink, res.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests signed, time-limited tokens carried in the password.
+func TestSignedTokenAuthenticator(t *testing.T) {
+	secret := []byte("server-secret")
+	valid := SignToken(secret, "guest", time.Now().Add(time.Hour))
+	tampered := []byte(valid)
+	tampered[strings.IndexByte(valid, '.')+1] ^= 1
+	tests := []struct {
+		name           string
+		username       string
+		password       string
+		skew           time.Duration
+		expectedStatus int
+	}{
+		{
+			name:           "test_valid_token",
+			username:       "guest",
+			password:       valid,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_expired_token",
+			username:       "guest",
+			password:       SignToken(secret, "guest", time.Now().Add(-time.Minute)),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_expired_token_within_skew",
+			username:       "guest",
+			password:       SignToken(secret, "guest", time.Now().Add(-time.Minute)),
+			skew:           5 * time.Minute,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_other_subject",
+			username:       "admin",
+			password:       valid,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_tampered_expiry",
+			username:       "guest",
+			password:       "9" + valid,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_tampered_signature",
+			username:       "guest",
+			password:       string(tampered),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_other_secret",
+			username:       "guest",
+			password:       SignToken([]byte("other-secret"), "guest", time.Now().Add(time.Hour)),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_malformed_token",
+			username:       "guest",
+			password:       "not-a-token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(nil)
+			auth.Authenticator = SignedTokenAuthenticator(secret, tc.skew)
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package basic
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// totpPeriod is the time step of the TOTP codes, as recommended by RFC 6238.
+const totpPeriod = 30
+
+// splitTOTP splits the TOTP code appended to the password of a user who has a TOTP secret in `UserTOTP`. The
+// boolean is false if the user has a secret but the password is too short to carry a code. Users without a
+// secret are returned as is with an empty code.
+func (a *BasicAuth) splitTOTP(username, password string) (string, string, bool) {
+	if _, ok := a.UserTOTP[username]; !ok {
+		return password, "", true
+	}
+
+	digits := a.totpDigits()
+	if len(password) < digits {
+		return "", "", false
+	}
+
+	return password[:len(password)-digits], password[len(password)-digits:], true
+}
+
+// totpUse is the last accepted TOTP step of a user, with a keyed hash of the credentials it was accepted with.
+type totpUse struct {
+	counter int64
+	key     string
+}
+
+// verifyTOTP verifies the TOTP code of a user (RFC 6238) within the tolerated time window, and marks it as used
+// so it cannot be replayed. Only call this after the password has been verified, so that attackers cannot burn
+// the codes of a user. The outcome is `resultReplayedCode` if the code is valid but has been consumed already.
+func (a *BasicAuth) verifyTOTP(username, password, code string) result {
+	counter, ok := a.matchTOTP(username, code)
+	if !ok {
+		return resultInvalidCredentials
+	}
+
+	if !a.consumeTOTP(username, password, counter) {
+		return resultReplayedCode
+	}
+
+	return resultSuccess
+}
+
+// matchTOTP checks a TOTP code of a user against the steps allowed by `TOTPSkew`, and returns the step it matches.
+// The code is not recorded as used, see `consumeTOTP`.
+func (a *BasicAuth) matchTOTP(username, code string) (int64, bool) {
+	key, err := decodeTOTPSecret(a.UserTOTP[username])
+	if err != nil {
+		return 0, false
+	}
+
+	digits := a.totpDigits()
+	current := a.now().Unix() / totpPeriod
+	for counter := current - int64(a.TOTPSkew); counter <= current+int64(a.TOTPSkew); counter++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, counter, digits)), []byte(code)) == 1 {
+			return counter, true
+		}
+	}
+
+	return 0, false
+}
+
+// consumeTOTP records the step of an accepted TOTP code. Basic clients send the same credentials on every request,
+// so the code of the last accepted step can be used again with the same password until the step is over. A code
+// from an earlier step, or from the same step with other credentials, is a replay, and is rejected.
+func (a *BasicAuth) consumeTOTP(username, password string, counter int64) bool {
+	key := credentialsKey(username, password)
+
+	a.totpMu.Lock()
+	defer a.totpMu.Unlock()
+
+	if last, ok := a.totpLastUsed[username]; ok {
+		if counter < last.counter || (counter == last.counter && !hmac.Equal([]byte(key), []byte(last.key))) {
+			return false
+		}
+	}
+
+	if a.totpLastUsed == nil {
+		a.totpLastUsed = make(map[string]totpUse)
+	}
+	a.totpLastUsed[username] = totpUse{counter: counter, key: key}
+
+	return true
+}
+
+// totpDigits returns the number of digits of the TOTP codes, defaulting to six.
+func (a *BasicAuth) totpDigits() int {
+	if a.TOTPDigits <= 0 {
+		return 6
+	}
+
+	return a.TOTPDigits
+}
+
+// decodeTOTPSecret decodes a base32 TOTP secret, as shown by most authenticator apps. Spaces, padding, and
+// lowercase letters are tolerated.
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.NewReplacer(" ", "", "=", "").Replace(secret))
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+}
+
+// hotp generates a HOTP code (RFC 4226) for a key and a counter, zero-padded to the given number of digits.
+func hotp(key []byte, counter int64, digits int) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, as specified in RFC 4226 section 5.3.
+	offset := sum[len(sum)-1] & 0x0f
+	value := int64(binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff)
+
+	modulo := int64(1)
+	for i := 0; i < digits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, value%modulo)
+}
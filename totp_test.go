@@ -0,0 +1,166 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Tests the code generation against the SHA-1 test vectors of RFC 6238 (Appendix B).
+func TestHOTP(t *testing.T) {
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{unix: 59, expected: "94287082"},
+		{unix: 1111111109, expected: "07081804"},
+		{unix: 1111111111, expected: "14050471"},
+		{unix: 1234567890, expected: "89005924"},
+		{unix: 2000000000, expected: "69279037"},
+	}
+
+	for _, tc := range tests {
+		if code := hotp(key, tc.unix/totpPeriod, 8); code != tc.expected {
+			t.Errorf("Expected and actual codes are different at %v! Expected: %v. Got: %v.", tc.unix, tc.expected, code)
+		}
+	}
+}
+
+// Tests the second factor appended to the password.
+func TestTOTP(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1111111109, 0)
+	current := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name           string
+		username       string
+		passwords      []string
+		expectedStatus int
+	}{
+		{
+			name:           "test_valid_code",
+			username:       "gerysantoso",
+			passwords:      []string{"gerysantoso" + hotp(key, current, 6)},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_valid_code_within_skew",
+			username:       "gerysantoso",
+			passwords:      []string{"gerysantoso" + hotp(key, current-1, 6)},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_invalid_code",
+			username:       "gerysantoso",
+			passwords:      []string{"gerysantoso" + "000000"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_expired_code",
+			username:       "gerysantoso",
+			passwords:      []string{"gerysantoso" + hotp(key, current-5, 6)},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_valid_code_wrong_password",
+			username:       "gerysantoso",
+			passwords:      []string{"wrong_password" + hotp(key, current, 6)},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_missing_code",
+			username:       "gerysantoso",
+			passwords:      []string{"gerysantoso"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_password_shorter_than_code",
+			username:       "gerysantoso",
+			passwords:      []string{"123"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_reused_code_within_step",
+			username:       "gerysantoso",
+			passwords:      []string{"gerysantoso" + hotp(key, current, 6), "gerysantoso" + hotp(key, current, 6)},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_replayed_older_code",
+			username:       "gerysantoso",
+			passwords:      []string{"gerysantoso" + hotp(key, current, 6), "gerysantoso" + hotp(key, current-1, 6)},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_user_without_secret",
+			username:       "a_username",
+			passwords:      []string{"a_password"},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso", "a_username": "a_password"})
+			auth.UserTOTP = map[string]string{"gerysantoso": "gezd gnbv gy3t qojq gezd gnbv gy3t qojq"}
+			auth.clock = func() time.Time { return now }
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			w := httptest.NewRecorder()
+			for _, password := range tc.passwords {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.SetBasicAuth(tc.username, password)
+				w = httptest.NewRecorder()
+
+				handler(w, r)
+			}
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests that a code is only accepted again with the credentials it was first accepted with, and that rejected
+// replays do not count toward the lockout.
+func TestTOTPReplay(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1111111109, 0)
+	code := hotp(key, now.Unix()/totpPeriod, 6)
+
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"})
+	auth.UserTOTP = map[string]string{"gerysantoso": "gezd gnbv gy3t qojq gezd gnbv gy3t qojq"}
+	auth.MaxFailures = 1
+	auth.LockoutDuration = time.Minute
+	auth.clock = func() time.Time { return now }
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	send := func(password string) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.SetBasicAuth("gerysantoso", password)
+		w := httptest.NewRecorder()
+		handler(w, r)
+
+		return w.Code
+	}
+
+	if status := send("gerysantoso" + code); status != http.StatusOK {
+		t.Fatalf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusOK, status)
+	}
+
+	// The same code with other (still valid) credentials is a replay.
+	auth.Users["gerysantoso"] = "rotated"
+	if status := send("rotated" + code); status != http.StatusUnauthorized {
+		t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusUnauthorized, status)
+	}
+
+	// The replay has not locked the user out.
+	auth.Users["gerysantoso"] = "gerysantoso"
+	if status := send("gerysantoso" + code); status != http.StatusOK {
+		t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", http.StatusOK, status)
+	}
+}
@@ -0,0 +1,119 @@
+package basic
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// AddUser adds a user to `Users`, or replaces its password if it already exists. It is safe to call concurrently
+// with requests, unlike writing to `Users` directly, so users can be added while serving. Credentials remembered by
+// `ConnectionCache` are verified again afterwards, so a rotated password stops working immediately. If the passwords
+// of `Users` are plaintext (neither `PasswordVerifier` nor `PasswordComparator` is set), the password has to satisfy
+// `PasswordPolicy`, otherwise the user is not added and an error wrapping `ErrWeakPassword` is returned. Stored
+// values which are hashes cannot be checked, so hash them with a helper checking the policy instead, such as
+// `basicbcrypt.AddUser`.
+func (a *BasicAuth) AddUser(username, password string) error {
+	if a.PasswordVerifier == nil && a.PasswordComparator == nil {
+		if err := a.PasswordPolicy.Check(password); err != nil {
+			return err
+		}
+	}
+
+	a.usersMu.Lock()
+	defer a.usersMu.Unlock()
+
+	if a.Users == nil {
+		a.Users = make(map[string]string)
+	}
+
+	a.Users[username] = password
+	atomic.AddUint32(&a.generation, 1)
+
+	return nil
+}
+
+// RemoveUser removes a user from `Users`, if it exists. It is safe to call concurrently with requests, so users
+// can be revoked while serving. Credentials remembered by `ConnectionCache` are verified again afterwards.
+func (a *BasicAuth) RemoveUser(username string) {
+	a.usersMu.Lock()
+	defer a.usersMu.Unlock()
+
+	delete(a.Users, username)
+	atomic.AddUint32(&a.generation, 1)
+}
+
+// LookupUser returns the stored password of a user in `Users`. It is safe to call concurrently with `AddUser` and
+// `RemoveUser`, and is meant for custom authenticators built on top of `Users`.
+func (a *BasicAuth) LookupUser(username string) (string, bool) {
+	a.usersMu.RLock()
+	defer a.usersMu.RUnlock()
+
+	stored, ok := a.Users[username]
+
+	return stored, ok
+}
+
+// countUsers returns the number of users in `Users`.
+func (a *BasicAuth) countUsers() int {
+	a.usersMu.RLock()
+	defer a.usersMu.RUnlock()
+
+	return len(a.Users)
+}
+
+// unnormalizedUsers returns the sorted usernames of `Users` which are changed by the username normalization, and can
+// therefore never be authenticated by the default authenticator.
+func (a *BasicAuth) unnormalizedUsers() []string {
+	a.usersMu.RLock()
+	defer a.usersMu.RUnlock()
+
+	var usernames []string
+	for _, username := range sortedKeys(a.Users) {
+		if a.normalizeUsername(username) != username {
+			usernames = append(usernames, username)
+		}
+	}
+
+	return usernames
+}
+
+// emptyPasswordUsers returns the usernames of `Users` with an empty password, in order.
+func (a *BasicAuth) emptyPasswordUsers() []string {
+	a.usersMu.RLock()
+	defer a.usersMu.RUnlock()
+
+	var usernames []string
+	for _, username := range sortedKeys(a.Users) {
+		if a.storedPassword(a.Users[username]) == "" {
+			usernames = append(usernames, username)
+		}
+	}
+
+	return usernames
+}
+
+// untrimmedUsers returns the usernames of `Users` whose stored password has leading or trailing white space, such as
+// the trailing newline of a value loaded from a file or a secret, in order.
+func (a *BasicAuth) untrimmedUsers() []string {
+	a.usersMu.RLock()
+	defer a.usersMu.RUnlock()
+
+	var usernames []string
+	for _, username := range sortedKeys(a.Users) {
+		if stored := a.Users[username]; strings.TrimSpace(stored) != stored {
+			usernames = append(usernames, username)
+		}
+	}
+
+	return usernames
+}
+
+// storedPassword returns a value of `Users` as it is verified, which is without its surrounding white space if
+// `TrimStoredPasswords` is set.
+func (a *BasicAuth) storedPassword(stored string) string {
+	if a.TrimStoredPasswords {
+		return strings.TrimSpace(stored)
+	}
+
+	return stored
+}
@@ -0,0 +1,125 @@
+package basic
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// Tests that users can be added and removed while requests are being authenticated. Run with `-race`.
+func TestAddRemoveUser(t *testing.T) {
+	auth := NewDefaultBasicAuth(nil)
+	handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	status := func(username, password string) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		r.SetBasicAuth(username, password)
+		handler(w, r)
+
+		return w.Code
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				username := "user" + strconv.Itoa(i*100+j)
+				auth.AddUser(username, username)
+				auth.RemoveUser(username)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				username := "user" + strconv.Itoa(i*100+j)
+				status(username, username)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	tests := []struct {
+		name           string
+		change         func()
+		expectedStatus int
+	}{
+		{name: "test_unknown_user", change: func() {}, expectedStatus: http.StatusUnauthorized},
+		{name: "test_added_user", change: func() { auth.AddUser("gerysantoso", "gerysantoso") }, expectedStatus: http.StatusOK},
+		{name: "test_replaced_password", change: func() { auth.AddUser("gerysantoso", "changed") }, expectedStatus: http.StatusUnauthorized},
+		{name: "test_removed_user", change: func() { auth.RemoveUser("gerysantoso") }, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.change()
+
+			if code := status("gerysantoso", "gerysantoso"); code != tc.expectedStatus {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, code)
+			}
+		})
+	}
+}
+
+// Tests that stored passwords with a trailing newline, such as the ones loaded from files or secrets, are only
+// accepted if `TrimStoredPasswords` is set.
+func TestTrimStoredPasswords(t *testing.T) {
+	tests := []struct {
+		name           string
+		stored         string
+		trim           bool
+		password       string
+		expectedStatus int
+	}{
+		{name: "test_trailing_newline_trimmed", stored: "gerysantoso\n", trim: true, password: "gerysantoso", expectedStatus: http.StatusOK},
+		{name: "test_surrounding_white_space_trimmed", stored: " gerysantoso\r\n", trim: true, password: "gerysantoso", expectedStatus: http.StatusOK},
+		{name: "test_trailing_newline_compared_as_is", stored: "gerysantoso\n", trim: false, password: "gerysantoso", expectedStatus: http.StatusUnauthorized},
+		{name: "test_trailing_newline_sent_by_client", stored: "gerysantoso\n", trim: true, password: "gerysantoso\n", expectedStatus: http.StatusUnauthorized},
+		{name: "test_white_space_only_trimmed", stored: "\n", trim: true, password: " ", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": tc.stored})
+			auth.TrimStoredPasswords = tc.trim
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth("gerysantoso", tc.password)
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// Tests that `AddUser` rejects plaintext passwords which do not satisfy `PasswordPolicy`, and keeps the previous ones.
+func TestAddUserPasswordPolicy(t *testing.T) {
+	auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "Correct-Horse-9"})
+	auth.PasswordPolicy = PasswordPolicy{MinPasswordLen: 12, RequireDigits: true}
+
+	if err := auth.AddUser("gerysantoso", "weak"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("Expected and actual errors are different! Expected: %v. Got: %v.", ErrWeakPassword, err)
+	}
+
+	if stored, _ := auth.LookupUser("gerysantoso"); stored != "Correct-Horse-9" {
+		t.Errorf("Expected and actual stored passwords are different! Expected: %v. Got: %v.", "Correct-Horse-9", stored)
+	}
+
+	if err := auth.AddUser("nicholasdwiarto", "long enough 1"); err != nil {
+		t.Errorf("Expected the password to satisfy the policy. Got: %v.", err)
+	}
+
+	// Hashes cannot be checked, so they are left to the hashing helpers.
+	auth.PasswordComparator = func(supplied, stored string) bool { return supplied == stored }
+	if err := auth.AddUser("fakeuser", "weak"); err != nil {
+		t.Errorf("Expected stored values which are not plaintext not to be checked. Got: %v.", err)
+	}
+}
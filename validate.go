@@ -0,0 +1,207 @@
+package basic
+
+import (
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// Validate checks the configuration for mistakes, and returns all the problems found at once, so they can be fixed
+// in a single pass. It returns `nil` if the configuration is valid. It is meant to be called once at startup, such
+// as right after building the configuration, and does not need to be called for the middleware to work.
+func (a *BasicAuth) Validate() []error {
+	var errs []error
+	addError := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("basic: "+format, args...))
+	}
+
+	// The challenge. Double quotes and backslashes of the realm are escaped, but control characters are dropped.
+	if strings.IndexFunc(a.Realm, isControl) >= 0 {
+		addError("realm %q contains control characters, which are dropped from the challenge", a.Realm)
+	}
+
+	if strings.ContainsAny(a.Charset, "\"\\") || strings.IndexFunc(a.Charset, isControl) >= 0 {
+		addError("charset %q contains double quotes, backslashes, or control characters", a.Charset)
+	} else if a.Charset != "" && !strings.EqualFold(a.Charset, "UTF-8") && !isLatin1(a.Charset) {
+		addError("charset %q is not allowed, as RFC 7617 only allows 'UTF-8' (and 'ISO-8859-1' is supported for legacy clients)", a.Charset)
+	}
+
+	for _, name := range sortedKeys(a.ChallengeParams) {
+		if !isToken(name) {
+			addError("challenge parameter %q is not a valid token, and will be skipped", name)
+		}
+	}
+
+	if a.TokenEndpoint != "" {
+		if _, err := linkTarget(a.TokenEndpoint); err != nil {
+			addError("token endpoint %q is not a valid URI reference, and will not be sent: %v", a.TokenEndpoint, err)
+		}
+	}
+
+	// The authenticators.
+	if a.usesDefaultAuthenticator() && a.countUsers() == 0 {
+		addError("no users and no authenticator are set, so every request will be rejected")
+	}
+
+	if a.usesDefaultAuthenticator() {
+		for _, username := range a.unnormalizedUsers() {
+			addError("user %q is changed by the username normalization, and will never be authenticated", username)
+		}
+
+		if !a.AllowEmptyPassword {
+			for _, username := range a.emptyPasswordUsers() {
+				addError("user %q has an empty password, and will never be authenticated unless AllowEmptyPassword is set", username)
+			}
+		}
+
+		if !a.TrimStoredPasswords {
+			for _, username := range a.untrimmedUsers() {
+				addError("the password of user %q has surrounding white space (such as a trailing newline), which is compared as is unless TrimStoredPasswords is set", username)
+			}
+		}
+	}
+
+	for _, username := range a.DeniedUsers {
+		if a.normalizeUsername(username) != username {
+			addError("denied user %q is changed by the username normalization, and will never be matched", username)
+		}
+	}
+
+	if a.PasswordVerifier != nil && a.PasswordComparator != nil {
+		addError("both PasswordVerifier and PasswordComparator are set, but PasswordComparator will never be called")
+	}
+
+	if a.AuthenticatorWithRequest != nil && a.AuthenticatorE != nil {
+		addError("both AuthenticatorWithRequest and AuthenticatorE are set, but AuthenticatorE will never be called")
+	}
+
+	if a.AuthenticatorWithRequest != nil && a.AuthenticatorCtx != nil {
+		addError("both AuthenticatorWithRequest and AuthenticatorCtx are set, but AuthenticatorCtx will never be called")
+	}
+
+	if a.AuthenticatorCtx != nil && a.AuthenticatorE != nil {
+		addError("both AuthenticatorCtx and AuthenticatorE are set, but AuthenticatorE will never be called")
+	}
+
+	if a.AuthenticatorCtx != nil && (a.CoalesceAuthentications || a.CacheTTL > 0) {
+		addError("CoalesceAuthentications and CacheTTL have no effect with AuthenticatorCtx")
+	}
+
+	if a.AuthenticatorWithRequest != nil && a.CoalesceAuthentications {
+		addError("CoalesceAuthentications has no effect with AuthenticatorWithRequest")
+	}
+
+	if a.AuthenticatorWithRequest != nil && a.CacheTTL > 0 {
+		addError("CacheTTL has no effect with AuthenticatorWithRequest")
+	}
+
+	if a.CacheSize < 0 {
+		addError("CacheSize cannot be negative")
+	}
+
+	// The failure responses, which would panic if missing.
+	if a.InternalErrorResponse == nil {
+		addError("InternalErrorResponse is not set")
+	}
+
+	if a.InvalidCredentialsResponse == nil {
+		addError("InvalidCredentialsResponse is not set")
+	}
+
+	if a.InvalidRequestResponse == nil {
+		addError("InvalidRequestResponse is not set")
+	}
+
+	if a.InvalidSchemeResponse == nil {
+		addError("InvalidSchemeResponse is not set")
+	}
+
+	if a.InvalidCredentialsStatus != 0 && (a.InvalidCredentialsStatus < 400 || a.InvalidCredentialsStatus > 599) {
+		addError("InvalidCredentialsStatus %d is not a client or server error status code", a.InvalidCredentialsStatus)
+	}
+
+	if a.InvalidSchemeStatus != 0 && (a.InvalidSchemeStatus < 400 || a.InvalidSchemeStatus > 599) {
+		addError("InvalidSchemeStatus %d is not a client or server error status code", a.InvalidSchemeStatus)
+	}
+
+	// The limits and the brute-force protection.
+	if a.MaxUsernameLen < 0 || a.MaxPasswordLen < 0 || a.MaxCredentialLength < 0 {
+		addError("MaxUsernameLen, MaxPasswordLen, and MaxCredentialLength cannot be negative")
+	}
+
+	if (a.MaxFailures > 0) != (a.LockoutDuration > 0) {
+		addError("MaxFailures and LockoutDuration have to be set together for the lockout to be enabled")
+	}
+
+	for _, contentType := range a.RequireContentTypes {
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			addError("content type %q is not a valid media type: %v", contentType, err)
+		}
+	}
+
+	for _, hash := range sortedKeys(a.CompromisedPasswords) {
+		if len(hash) != 40 || strings.ToUpper(hash) != hash {
+			addError("compromised password %q is not an uppercase hex SHA-1 hash", hash)
+		} else if _, err := hex.DecodeString(hash); err != nil {
+			addError("compromised password %q is not an uppercase hex SHA-1 hash", hash)
+		}
+	}
+
+	// The second factor.
+	if a.TOTPDigits < 0 || a.TOTPDigits > 10 {
+		addError("TOTPDigits has to be between 1 and 10, or zero for the default")
+	}
+
+	if a.TOTPSkew < 0 {
+		addError("TOTPSkew cannot be negative")
+	}
+
+	for _, username := range sortedKeys(a.UserTOTP) {
+		if _, err := decodeTOTPSecret(a.UserTOTP[username]); err != nil {
+			addError("TOTP secret of user %q is not valid base32: %v", username, err)
+		}
+	}
+
+	return errs
+}
+
+// usesDefaultAuthenticator checks whether the requests are verified against `Users`, as no other authenticator is set.
+// The constructors store a closure of their own in `Authenticator`, which is told apart from a custom authenticator
+// by its identity, as functions cannot be compared.
+func (a *BasicAuth) usesDefaultAuthenticator() bool {
+	if _, ok := a.loadSwapped(); ok {
+		return false
+	}
+
+	if a.AuthenticatorWithRequest != nil || a.AuthenticatorCtx != nil || a.AuthenticatorE != nil {
+		return false
+	}
+
+	return a.Authenticator == nil || sameFunc(a.Authenticator, a.defaultAuthenticator)
+}
+
+// sameFunc checks whether two function values are the very same closure. Unlike comparing their code pointers with
+// `reflect`, which are shared by all the closures (and method values) of the same function, it tells the closures of
+// different instances apart.
+func sameFunc(a, b func(username, password string) bool) bool {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&a)) == *(*unsafe.Pointer)(unsafe.Pointer(&b))
+}
+
+// isControl checks whether a character is a control character, which cannot appear in a header.
+func isControl(c rune) bool {
+	return c < ' ' || c == 0x7f
+}
+
+// sortedKeys returns the keys of a map in order, so the problems are reported deterministically.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
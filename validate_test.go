@@ -0,0 +1,205 @@
+package basic
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests that all the problems of a configuration are reported at once.
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		configure      func(auth *BasicAuth)
+		expectedErrors []string
+	}{
+		{
+			name:           "test_default_configuration",
+			configure:      func(auth *BasicAuth) {},
+			expectedErrors: nil,
+		},
+		{
+			name: "test_custom_authenticator_without_users",
+			configure: func(auth *BasicAuth) {
+				auth.Users = nil
+				auth.Authenticator = func(username, password string) bool { return true }
+			},
+			expectedErrors: nil,
+		},
+		{
+			name: "test_multiple_misconfigurations",
+			configure: func(auth *BasicAuth) {
+				auth.Realm = "Private\r\nInjected: true"
+				auth.Charset = "Shift_JIS"
+				auth.TokenEndpoint = "http://[::1"
+				auth.Users = nil
+				auth.MaxFailures = 5
+				auth.TOTPSkew = -1
+				auth.UserTOTP = map[string]string{"gerysantoso": "not base32!"}
+				auth.CompromisedPasswords = map[string]struct{}{"5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8": {}}
+				auth.InvalidSchemeResponse = nil
+			},
+			expectedErrors: []string{
+				"realm",
+				"charset",
+				"token endpoint",
+				"no users and no authenticator",
+				"InvalidSchemeResponse",
+				"MaxFailures and LockoutDuration",
+				"compromised password",
+				"TOTPSkew",
+				"TOTP secret of user",
+			},
+		},
+		{
+			name: "test_authenticator_of_another_instance",
+			configure: func(auth *BasicAuth) {
+				auth.Users = nil
+				auth.Authenticator = NewDefaultBasicAuth(map[string]string{"gerysantoso": "gerysantoso"}).verifyUser
+			},
+			expectedErrors: nil,
+		},
+		{
+			name: "test_wrapped_default_authenticator",
+			configure: func(auth *BasicAuth) {
+				auth.Users = nil
+				authenticator := auth.Authenticator
+				auth.Authenticator = func(username, password string) bool {
+					return username == "gerysantoso" || authenticator(username, password)
+				}
+			},
+			expectedErrors: nil,
+		},
+		{
+			name: "test_realm_with_quotes",
+			configure: func(auth *BasicAuth) {
+				auth.Realm = `The "Private" \ Realm`
+			},
+			expectedErrors: nil,
+		},
+		{
+			name: "test_charset_breaking_the_challenge",
+			configure: func(auth *BasicAuth) {
+				auth.Charset = "UTF-8\"\r\nX-Injected: 1"
+			},
+			expectedErrors: []string{
+				`charset "UTF-8\"\r\nX-Injected: 1" contains double quotes, backslashes, or control characters`,
+			},
+		},
+		{
+			name: "test_invalid_failure_statuses",
+			configure: func(auth *BasicAuth) {
+				auth.InvalidCredentialsStatus = http.StatusOK
+				auth.InvalidSchemeStatus = 1000
+			},
+			expectedErrors: []string{
+				"InvalidCredentialsStatus 200 is not a client or server error status code",
+				"InvalidSchemeStatus 1000 is not a client or server error status code",
+			},
+		},
+		{
+			name: "test_conflicting_context_authenticator",
+			configure: func(auth *BasicAuth) {
+				auth.AuthenticatorCtx = func(ctx context.Context, username, password string) (bool, error) { return true, nil }
+				auth.AuthenticatorE = func(username, password string) (bool, error) { return true, nil }
+				auth.CacheTTL = time.Minute
+			},
+			expectedErrors: []string{
+				"AuthenticatorE will never be called",
+				"CoalesceAuthentications and CacheTTL have no effect with AuthenticatorCtx",
+			},
+		},
+		{
+			name: "test_empty_passwords",
+			configure: func(auth *BasicAuth) {
+				auth.Users = map[string]string{"gerysantoso": "gerysantoso", "legacy": ""}
+			},
+			expectedErrors: []string{
+				`user "legacy" has an empty password`,
+			},
+		},
+		{
+			name: "test_empty_passwords_allowed",
+			configure: func(auth *BasicAuth) {
+				auth.Users = map[string]string{"gerysantoso": "gerysantoso", "legacy": ""}
+				auth.AllowEmptyPassword = true
+			},
+			expectedErrors: nil,
+		},
+		{
+			name: "test_untrimmed_passwords",
+			configure: func(auth *BasicAuth) {
+				auth.Users = map[string]string{"gerysantoso": "gerysantoso\n", "nicholasdwiarto": "nicholasdwiarto"}
+			},
+			expectedErrors: []string{
+				`the password of user "gerysantoso" has surrounding white space`,
+			},
+		},
+		{
+			name: "test_untrimmed_passwords_trimmed",
+			configure: func(auth *BasicAuth) {
+				auth.Users = map[string]string{"gerysantoso": "gerysantoso\n", "legacy": "\n"}
+				auth.TrimStoredPasswords = true
+			},
+			expectedErrors: []string{
+				`user "legacy" has an empty password`,
+			},
+		},
+		{
+			name: "test_unnormalized_users",
+			configure: func(auth *BasicAuth) {
+				auth.UsernameCaseFold = true
+				auth.Users = map[string]string{"GerySantoso": "gerysantoso", "nicholasdwiarto": "nicholasdwiarto"}
+				auth.DeniedUsers = []string{"Root", "admin"}
+			},
+			expectedErrors: []string{
+				`user "GerySantoso" is changed by the username normalization`,
+				`denied user "Root" is changed by the username normalization`,
+			},
+		},
+		{
+			name: "test_conflicting_password_comparisons",
+			configure: func(auth *BasicAuth) {
+				auth.PasswordVerifier = PlaintextVerifier{}
+				auth.PasswordComparator = CompareInputs
+			},
+			expectedErrors: []string{
+				"PasswordComparator will never be called",
+			},
+		},
+		{
+			name: "test_conflicting_authenticators",
+			configure: func(auth *BasicAuth) {
+				auth.AuthenticatorWithRequest = func(r *http.Request, username, password string) bool { return true }
+				auth.AuthenticatorE = func(username, password string) (bool, error) { return true, nil }
+				auth.CoalesceAuthentications = true
+				auth.MaxFailures = 5
+				auth.LockoutDuration = time.Minute
+			},
+			expectedErrors: []string{
+				"AuthenticatorE will never be called",
+				"CoalesceAuthentications has no effect",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewCustomBasicAuth(nil, "UTF-8", nil, nil, "Private", map[string]string{"gerysantoso": "gerysantoso"})
+			tc.configure(auth)
+
+			errs := auth.Validate()
+			if len(errs) != len(tc.expectedErrors) {
+				t.Fatalf("Expected and actual numbers of errors are different! Expected: %v. Got: %v (%v).", len(tc.expectedErrors), len(errs), errs)
+			}
+
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tc.expectedErrors[i]) {
+					t.Errorf("Expected the error to mention %q. Got: %v.", tc.expectedErrors[i], err)
+				}
+			}
+		})
+	}
+}
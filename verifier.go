@@ -0,0 +1,64 @@
+package basic
+
+import "encoding/hex"
+
+// PasswordVerifier verifies a password supplied by a client against the value stored in `Users`. It is used by
+// the default authenticator, so any hashing scheme (bcrypt, argon2, scrypt, and the like) can be brought in by
+// the user without adding dependencies to this package. An error is treated as a failed verification. Unknown
+// usernames are verified against a random plaintext value, which a hashing verifier should reject as slowly as a
+// wrong password, rather than failing fast on it as malformed (see `basicbcrypt.NewBasicAuth` for a way around it).
+type PasswordVerifier interface {
+	Verify(password, stored string) (bool, error)
+}
+
+// PlaintextVerifier is the built-in `PasswordVerifier`, which expects the values of `Users` to be plaintext
+// passwords, and compares them with `CompareInputs` (SHA-256 and constant-time comparison).
+type PlaintextVerifier struct{}
+
+// Verify compares the supplied password with the stored one in constant time.
+func (PlaintextVerifier) Verify(password, stored string) (bool, error) {
+	return CompareInputs(password, stored), nil
+}
+
+// comparatorVerifier adapts `PasswordComparator` to a `PasswordVerifier`, which never reports errors.
+type comparatorVerifier func(supplied, stored string) bool
+
+// Verify compares the supplied password with the stored value with the comparator.
+func (compare comparatorVerifier) Verify(password, stored string) (bool, error) {
+	return compare(password, stored), nil
+}
+
+// dummyPassword is a random value generated once per process, which unknown usernames are verified against.
+var dummyPassword = hex.EncodeToString(newProcessKey())
+
+// verifyUser is the default authenticator. It checks whether the username exists in `Users`, and then verifies
+// the password with `PasswordVerifier` or `PasswordComparator`. An unknown username is still verified, against `dummyPassword`, and only
+// rejected afterwards, so it takes as long as a known one, and the time taken does not tell which usernames exist.
+func (a *BasicAuth) verifyUser(username, password string) bool {
+	stored, found := a.LookupUser(username)
+	if !found {
+		stored = dummyPassword
+	}
+	stored = a.storedPassword(stored)
+
+	// The verifier, which can report errors, takes precedence over the simpler comparator.
+	var verifier PasswordVerifier = PlaintextVerifier{}
+	if a.PasswordVerifier != nil {
+		verifier = a.PasswordVerifier
+	} else if a.PasswordComparator != nil {
+		verifier = comparatorVerifier(a.PasswordComparator)
+	}
+
+	// Normalization is not constant-time, so it has to run before the comparison, and may leak the shape of the
+	// passwords (such as whether they contain decomposed characters), but not whether they match.
+	if a.PasswordUnicodeNormalizer != nil {
+		password = a.PasswordUnicodeNormalizer(password)
+		if verifier == (PlaintextVerifier{}) {
+			stored = a.PasswordUnicodeNormalizer(stored)
+		}
+	}
+
+	valid, err := verifier.Verify(password, stored)
+
+	return found && err == nil && valid
+}
@@ -0,0 +1,228 @@
+package basic
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// sha256Verifier is a custom verifier, where users are stored as hex-encoded SHA-256 hashes.
+type sha256Verifier struct{}
+
+// Verify hashes the supplied password and compares it with the stored hash.
+func (sha256Verifier) Verify(password, stored string) (bool, error) {
+	hash := sha256.Sum256([]byte(password))
+
+	return CompareInputs(hex.EncodeToString(hash[:]), stored), nil
+}
+
+// failingVerifier is a custom verifier which always fails, such as a verifier with a malformed stored hash.
+type failingVerifier struct{}
+
+// Verify always returns an error, even if the verdict is positive.
+func (failingVerifier) Verify(password, stored string) (bool, error) {
+	return true, errors.New("malformed stored hash")
+}
+
+// Tests the injection of a custom password verifier in the default authenticator.
+func TestPasswordVerifier(t *testing.T) {
+	hash := sha256.Sum256([]byte("gerysantoso"))
+	users := map[string]string{"gerysantoso": hex.EncodeToString(hash[:])}
+
+	tests := []struct {
+		name           string
+		verifier       PasswordVerifier
+		username       string
+		password       string
+		expectedStatus int
+	}{
+		{
+			name:           "test_custom_verifier_valid_password",
+			verifier:       sha256Verifier{},
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_custom_verifier_invalid_password",
+			verifier:       sha256Verifier{},
+			username:       "gerysantoso",
+			password:       "wrong_password",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_custom_verifier_unknown_user",
+			verifier:       sha256Verifier{},
+			username:       "unknown",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_custom_verifier_error",
+			verifier:       failingVerifier{},
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_default_verifier_compares_plaintext",
+			verifier:       nil,
+			username:       "gerysantoso",
+			password:       hex.EncodeToString(hash[:]),
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(users)
+			auth.PasswordVerifier = tc.verifier
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+// recordingVerifier is a custom verifier which accepts any password, and records the stored values it is given.
+type recordingVerifier struct {
+	stored []string
+}
+
+// Verify records the stored value, and always accepts the password.
+func (v *recordingVerifier) Verify(password, stored string) (bool, error) {
+	v.stored = append(v.stored, stored)
+
+	return true, nil
+}
+
+// Tests that unknown usernames go through the same verification as known ones, against a dummy value, so the time
+// taken does not tell which usernames exist. Timing itself is too noisy to be asserted here, so this checks that the
+// verifier is called exactly once either way, and that its verdict cannot let an unknown username in.
+func TestVerifyUnknownUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		username       string
+		expectedStored string
+		expectedValid  bool
+	}{
+		{
+			name:           "test_known_user",
+			username:       "gerysantoso",
+			expectedStored: "stored",
+			expectedValid:  true,
+		},
+		{
+			name:           "test_unknown_user",
+			username:       "nicholasdwiarto",
+			expectedStored: dummyPassword,
+			expectedValid:  false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier := &recordingVerifier{}
+			auth := NewDefaultBasicAuth(map[string]string{"gerysantoso": "stored"})
+			auth.PasswordVerifier = verifier
+
+			if valid := auth.verifyUser(tc.username, "password"); valid != tc.expectedValid {
+				t.Errorf("Expected and actual results are different! Expected: %v. Got: %v.", tc.expectedValid, valid)
+			}
+
+			if len(verifier.stored) != 1 || verifier.stored[0] != tc.expectedStored {
+				t.Errorf("Expected and actual verified values are different! Expected: %v. Got: %v.", []string{tc.expectedStored}, verifier.stored)
+			}
+		})
+	}
+
+	if dummyPassword == "" {
+		t.Error("Expected the dummy password not to be empty, as it could match an empty password.")
+	}
+}
+
+// Tests the injection of a custom password comparator in the default authenticator, and its precedence.
+func TestPasswordComparator(t *testing.T) {
+	hash := sha256.Sum256([]byte("gerysantoso"))
+	users := map[string]string{"gerysantoso": hex.EncodeToString(hash[:])}
+	compareSHA256 := func(supplied, stored string) bool {
+		valid, _ := sha256Verifier{}.Verify(supplied, stored)
+		return valid
+	}
+
+	tests := []struct {
+		name           string
+		comparator     func(supplied, stored string) bool
+		verifier       PasswordVerifier
+		username       string
+		password       string
+		expectedStatus int
+	}{
+		{
+			name:           "test_custom_comparator_valid_password",
+			comparator:     compareSHA256,
+			username:       "gerysantoso",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test_custom_comparator_invalid_password",
+			comparator:     compareSHA256,
+			username:       "gerysantoso",
+			password:       "wrong_password",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_permissive_comparator_unknown_user",
+			comparator:     func(supplied, stored string) bool { return true },
+			username:       "unknown",
+			password:       "gerysantoso",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_verifier_takes_precedence",
+			comparator:     func(supplied, stored string) bool { return true },
+			verifier:       sha256Verifier{},
+			username:       "gerysantoso",
+			password:       "wrong_password",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "test_default_comparator_compares_plaintext",
+			comparator:     nil,
+			username:       "gerysantoso",
+			password:       hex.EncodeToString(hash[:]),
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewDefaultBasicAuth(users)
+			auth.PasswordComparator = tc.comparator
+			auth.PasswordVerifier = tc.verifier
+
+			handler := auth.Authenticate(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			r.SetBasicAuth(tc.username, tc.password)
+
+			handler(w, r)
+
+			if tc.expectedStatus != w.Code {
+				t.Errorf("Expected and actual status code values are different! Expected: %v. Got: %v.", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package basic
+
+// zero overwrites a buffer with zeroes. The buffers owned by this package which held credentials, such as the
+// decode buffers of the parser and the buffers of the pooled hashers, are zeroed as soon as they are no longer
+// needed, so that they do not linger in the heap, where they could be captured in a core dump or by memory
+// scraping. Strings are never copied just to be zeroed, as that would only add a copy: the credentials passed
+// around are Go strings, which are immutable and stay in memory until they are garbage collected.
+func zero(buffer []byte) {
+	for i := range buffer {
+		buffer[i] = 0
+	}
+}
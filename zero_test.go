@@ -0,0 +1,63 @@
+package basic
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+// Tests that the pooled buffers are zeroed after parsing, so that no credentials linger in them.
+func TestParseBasicAuthZeroesBuffers(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{
+			name:   "test_valid",
+			header: "Basic " + base64.StdEncoding.EncodeToString([]byte("gerysantoso:gerysantoso")),
+		},
+		{
+			name:   "test_missing_colon",
+			header: "Basic " + base64.StdEncoding.EncodeToString([]byte("gerysantoso")),
+		},
+		{
+			name:   "test_invalid_base64_after_valid_prefix",
+			header: "Basic " + base64.StdEncoding.EncodeToString([]byte("gerysantoso:gerysantoso"))[:12] + "!!!!",
+		},
+	}
+
+	// Inspect the very buffers used by the parser as they are released, instead of relying on the pool to hand
+	// them out again.
+	release := releaseCredentialBuffer
+	defer func() { releaseCredentialBuffer = release }()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var released *[]byte
+			releaseCredentialBuffer = func(buffer *[]byte) { released = buffer }
+
+			parseBasicAuth(tc.header, base64.StdEncoding)
+
+			if released == nil {
+				t.Fatal("Expected the parser to release a pooled buffer!")
+			}
+
+			for i, b := range *released {
+				if b != 0 {
+					t.Fatalf("Expected the pooled buffer to be zeroed! Got: %v at index %v.", b, i)
+				}
+			}
+		})
+	}
+}
+
+// Tests that buffers are overwritten with zeroes.
+func TestZero(t *testing.T) {
+	buffer := []byte("gerysantoso")
+	zero(buffer)
+
+	for i, b := range buffer {
+		if b != 0 {
+			t.Errorf("Expected the buffer to be zeroed! Got: %v at index %v.", b, i)
+		}
+	}
+}